@@ -0,0 +1,224 @@
+package prioqueue
+
+// bheapBlockBits is the height of the subtree in each block of a BHeap. Each
+// block holds 2^bheapBlockBits-1 items and one unused slot, i.e. 4 KiB which is
+// the size of a memory page on most systems. Note that the blocks are not
+// aligned to page boundaries, so a block usually spans two pages.
+const bheapBlockBits = 9
+
+// BHeap implements a priority queue which allows to retrieve the highest
+// priority element, just like the MaxHeap. It is optimized for very large
+// heaps where the classic array representation causes a cache miss or even a
+// TLB miss on almost every level of the binary tree, since the children of an
+// item are stored far away from it.
+//
+// Block representation
+//
+// The BHeap groups subtrees of the binary tree into blocks of contiguous
+// memory. Each block contains a complete binary tree of height h in the same
+// layout as the classic heap, except that the root is stored at index 1 of the
+// block and index 0 is left empty to align the blocks in memory. The children
+// of the leaves of a block are the roots of other blocks, so each block has 2^h
+// child blocks. The child blocks of block b are the blocks b*2^h+1 up to
+// b*2^h+2^h. This way, a walk from the root to a leaf only touches a new block
+// every h levels.
+//
+// Blocks are filled one after another, so the k-th item of the heap is stored
+// in block k/(2^h-1) at offset k%(2^h-1)+1. Since the parent of each item comes
+// before it in this order, the items always form a valid tree.
+//
+// Unlike the MaxHeap, the BHeap stores its items by value, so pushing an item
+// does not allocate unless the backing array is full and has to grow.
+//
+// The priority queue has the following properties:
+//   - items with high priority are dequeued before elements with lower priority
+//   - items with equal priority are dequeued in ascending order of their IDs
+//
+// Time Complexity
+//
+//   Push and Pop take O(log n) and Top() happens in constant time.
+type BHeap struct {
+	items []Item // the blocks, with 2^bheapBlockBits slots each
+	len   int
+}
+
+const (
+	bheapBlockSize  = 1 << bheapBlockBits // slots per block
+	bheapBlockItems = bheapBlockSize - 1  // items per block
+	bheapFirstLeaf  = bheapBlockSize / 2  // offset of the first leaf of a block
+	bheapOffsetMask = bheapBlockSize - 1  // mask of the offset in a position
+)
+
+// NewBHeap returns a new BHeap instance which contains a pre-allocated backing
+// array for the given amount of items. If the size is 0 or negative, the
+// backing array is allocated when the first item is pushed.
+func NewBHeap(size int) *BHeap {
+	h := new(BHeap)
+	if size > 0 {
+		blocks := (size + bheapBlockItems - 1) / bheapBlockItems
+		h.items = make([]Item, 0, blocks*bheapBlockSize)
+	}
+	return h
+}
+
+// Len returns the amount of elements in the queue.
+func (h *BHeap) Len() int {
+	return h.len
+}
+
+// Reset is a fast way to empty the queue. Note that the underlying array will
+// still be used by the heap which means that this function will not free up any
+// memory.
+func (h *BHeap) Reset() {
+	h.items = h.items[:0]
+	h.len = 0
+}
+
+// Top returns the ID and priority of the item with the highest priority value
+// in the queue without removing it.
+func (h *BHeap) Top() (id uint32, prio float32) {
+	if h.len == 0 {
+		return 0, 0
+	}
+
+	item := h.items[1]
+	return item.ID, item.Prio
+}
+
+// Push the value item into the priority queue with provided priority.
+func (h *BHeap) Push(id uint32, prio float32) {
+	item := Item{ID: id, Prio: prio}
+
+	p := bheapPosition(h.len)
+	if p >= len(h.items) {
+		// The last block is full, so we need to add a new one. The backing
+		// array is only reallocated if it has no room for another block.
+		n := len(h.items) + bheapBlockSize
+		if n > cap(h.items) {
+			items := make([]Item, len(h.items), 2*cap(h.items)+bheapBlockSize)
+			copy(items, h.items)
+			h.items = items
+		}
+		h.items = h.items[:n]
+	}
+	h.len++
+
+	h.shiftUp(p, item)
+}
+
+// Pop removes the item with the highest priority value from the queue and
+// returns its ID and priority.
+func (h *BHeap) Pop() (id uint32, prio float32) {
+	if h.len == 0 {
+		return 0, 0
+	}
+
+	root := h.items[1]
+	h.len--
+
+	last := bheapPosition(h.len)
+	if h.len > 0 {
+		h.shiftDown(1, h.items[last])
+	}
+	if last&bheapOffsetMask == 1 {
+		// The last block is empty now.
+		h.items = h.items[:last-1]
+	}
+
+	return root.ID, root.Prio
+}
+
+// PopAndPush removes the item with the highest priority value and adds a new
+// value to the heap in one operation. This is faster than two separate calls
+// to Pop and Push. If the queue is empty, the item is simply pushed.
+func (h *BHeap) PopAndPush(item *Item) {
+	if h.len == 0 {
+		h.Push(item.ID, item.Prio)
+		return
+	}
+
+	h.shiftDown(1, *item)
+}
+
+// shiftUp places the item at position p or one of its ancestors by moving the
+// ancestors with a lower priority down.
+func (h *BHeap) shiftUp(p int, item Item) {
+	for p != 1 {
+		parent := bheapParent(p)
+		if !maxFirst(&item, &h.items[parent]) {
+			break
+		}
+
+		h.items[p] = h.items[parent]
+		p = parent
+	}
+
+	h.items[p] = item
+}
+
+// shiftDown places the item at position p or one of its descendants by moving
+// the descendants with a higher priority up.
+func (h *BHeap) shiftDown(p int, item Item) {
+	for {
+		c := bheapChild(p)
+		if bheapIndex(c) >= h.len {
+			break // p has no children
+		}
+
+		// The second child follows the first child within the same block or
+		// it is the root of the block after the one of the first child.
+		second := c + 1
+		if c&bheapOffsetMask == 1 {
+			second = c + bheapBlockSize
+		}
+		if bheapIndex(second) < h.len && maxFirst(&h.items[second], &h.items[c]) {
+			c = second
+		}
+
+		if !maxFirst(&h.items[c], &item) {
+			break
+		}
+
+		h.items[p] = h.items[c]
+		p = c
+	}
+
+	h.items[p] = item
+}
+
+// bheapPosition returns the position in the backing array of the k-th item.
+func bheapPosition(k int) int {
+	return (k/bheapBlockItems)<<bheapBlockBits | (k%bheapBlockItems + 1)
+}
+
+// bheapIndex is the inverse of bheapPosition.
+func bheapIndex(p int) int {
+	return (p>>bheapBlockBits)*bheapBlockItems + p&bheapOffsetMask - 1
+}
+
+// bheapParent returns the position of the parent of the item at position p,
+// which must not be the root.
+func bheapParent(p int) int {
+	b, o := p>>bheapBlockBits, p&bheapOffsetMask
+	if o > 1 {
+		return b<<bheapBlockBits | o/2
+	}
+
+	// p is the root of block b. Its parent is a leaf of the parent block.
+	c := (b - 1) & bheapOffsetMask // b is the c-th child of the parent block
+	return ((b-1)>>bheapBlockBits)<<bheapBlockBits | (bheapFirstLeaf + c/2)
+}
+
+// bheapChild returns the position of the first child of the item at position
+// p. The second child is at the next position within the same block or at the
+// root of the next block.
+func bheapChild(p int) int {
+	b, o := p>>bheapBlockBits, p&bheapOffsetMask
+	if o < bheapFirstLeaf {
+		return b<<bheapBlockBits | 2*o
+	}
+
+	// p is a leaf of block b, so its children are the roots of child blocks.
+	c := 2 * (o - bheapFirstLeaf)
+	return (b<<bheapBlockBits+1+c)<<bheapBlockBits | 1
+}
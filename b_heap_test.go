@@ -0,0 +1,78 @@
+package prioqueue_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/fgrosse/prioqueue/prioqueuetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBHeap(t *testing.T) {
+	prioqueuetest.Run(t, func() prioqueuetest.PriorityQueue {
+		return prioqueue.NewBHeap(0)
+	}, prioqueuetest.MaxFirst)
+}
+
+func TestBHeap_Allocs(t *testing.T) {
+	const n = 3 * 511
+	h := prioqueue.NewBHeap(n)
+
+	// Filling and draining the pre-allocated blocks never allocates.
+	allocs := testing.AllocsPerRun(10, func() {
+		for i := uint32(0); i < n; i++ {
+			h.Push(i, float32(i%7))
+		}
+		for h.Len() > 0 {
+			h.Pop()
+		}
+	})
+	assert.Zero(t, allocs)
+}
+
+// TestBHeap_Large tests a heap which spans many levels of blocks.
+func TestBHeap_Large(t *testing.T) {
+	h := prioqueue.NewBHeap(100)
+	model := prioqueue.NewMaxHeap(0)
+	model.SetTieBreakByID(true)
+	rng := rand.New(rand.NewSource(42))
+
+	const n = 300_000
+	for i := 0; i < n; i++ {
+		prio := float32(rng.Intn(1000))
+		h.Push(uint32(i), prio)
+		model.Push(uint32(i), prio)
+	}
+	require.Equal(t, n, h.Len())
+
+	for i := 0; i < n; i++ {
+		// Replace every other item so PopAndPush is covered as well.
+		if i%2 == 0 {
+			prio := float32(rng.Intn(1000))
+			topID, topPrio := h.Top()
+			expectedID, expectedPrio := model.Top()
+			require.Equal(t, expectedID, topID)
+			require.Equal(t, expectedPrio, topPrio)
+
+			item := &prioqueue.Item{ID: uint32(n + i), Prio: prio}
+			h.PopAndPush(item)
+			model.PopAndPush(&prioqueue.Item{ID: item.ID, Prio: item.Prio})
+			continue
+		}
+
+		id, prio := h.Pop()
+		expectedID, expectedPrio := model.Pop()
+		require.Equal(t, expectedID, id)
+		require.Equal(t, expectedPrio, prio)
+	}
+
+	for h.Len() > 0 {
+		id, prio := h.Pop()
+		expectedID, expectedPrio := model.Pop()
+		require.Equal(t, expectedID, id)
+		require.Equal(t, expectedPrio, prio)
+	}
+	assert.Equal(t, 0, model.Len())
+}
@@ -3,9 +3,13 @@ package prioqueue_test
 import (
 	"container/heap"
 	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/fgrosse/prioqueue"
+	"github.com/fgrosse/prioqueue/prioqueuetest"
 )
 
 var randValues []float32
@@ -98,9 +102,24 @@ func BenchmarkMaxHeap_Push200_Preallocate(b *testing.B) {
 
 // BenchmarkMaxHeap_Pop200 tests how long it takes to pop all elements from a
 // MaxHeap implementation which contains 200 random elements.
+//
+// If the tests are built with the prioqueue_countcmp tag, the benchmark also
+// reports the number of comparisons between items per pop.
 func BenchmarkMaxHeap_Pop200(b *testing.B) {
+	benchmarkMaxHeapPop200(b, prioqueue.TopDown)
+}
+
+// BenchmarkMaxHeap_Pop200_BottomUp is like BenchmarkMaxHeap_Pop200 but uses
+// the BottomUp PopStrategy.
+func BenchmarkMaxHeap_Pop200_BottomUp(b *testing.B) {
+	benchmarkMaxHeapPop200(b, prioqueue.BottomUp)
+}
+
+func benchmarkMaxHeapPop200(b *testing.B, strategy prioqueue.PopStrategy) {
 	pq := prioqueue.NewMaxHeap(len(randValues))
+	pq.SetPopStrategy(strategy)
 
+	var comparisons uint64
 	b.ReportAllocs()
 	b.ResetTimer()
 
@@ -110,11 +129,19 @@ func BenchmarkMaxHeap_Pop200(b *testing.B) {
 		for i := 0; i < len(randValues); i++ {
 			pq.Push(uint32(i), randValues[i])
 		}
+		start, _ := prioqueue.Comparisons()
 		b.StartTimer()
 
 		for pq.Len() > 0 {
 			pq.Pop()
 		}
+
+		end, _ := prioqueue.Comparisons()
+		comparisons += end - start
+	}
+
+	if _, ok := prioqueue.Comparisons(); ok {
+		b.ReportMetric(float64(comparisons)/float64(b.N*len(randValues)), "cmps/pop")
 	}
 }
 
@@ -226,3 +253,464 @@ func BenchmarkStdlibHeap_Pop200(b *testing.B) {
 		}
 	}
 }
+
+// BenchmarkMaxHeap_PushPop200_Release tests how fast we can push and pop 200
+// elements if popped items are released back to the MaxHeap. In steady state
+// this does not allocate any new items.
+func BenchmarkMaxHeap_PushPop200_Release(b *testing.B) {
+	pq := prioqueue.NewMaxHeap(len(randValues))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for id := uint32(0); id < 200; id++ {
+			pq.Push(id, randValues[id])
+		}
+
+		for pq.Len() > 0 {
+			pq.Release(pq.PopItem())
+		}
+	}
+}
+
+// BenchmarkMaxHeap_PushPop200_NoRelease is the baseline for
+// BenchmarkMaxHeap_PushPop200_Release which allocates a new item on every push.
+func BenchmarkMaxHeap_PushPop200_NoRelease(b *testing.B) {
+	pq := prioqueue.NewMaxHeap(len(randValues))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for id := uint32(0); id < 200; id++ {
+			pq.Push(id, randValues[id])
+		}
+
+		for pq.Len() > 0 {
+			pq.PopItem()
+		}
+	}
+}
+
+// BenchmarkSortItems_200 tests how fast SortItems sorts 200 random items.
+func BenchmarkSortItems_200(b *testing.B) {
+	values := make([]*prioqueue.Item, len(randValues))
+	items := make([]*prioqueue.Item, len(randValues))
+	for i := range values {
+		values[i] = &prioqueue.Item{ID: uint32(i), Prio: randValues[i]}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		copy(items, values)
+		prioqueue.SortItems(items, true)
+	}
+}
+
+// BenchmarkPartialSort_200_Top10 tests how fast PartialSort finds and sorts the
+// 10 highest of 200 random items.
+func BenchmarkPartialSort_200_Top10(b *testing.B) {
+	values := make([]*prioqueue.Item, len(randValues))
+	items := make([]*prioqueue.Item, len(randValues))
+	for i := range values {
+		values[i] = &prioqueue.Item{ID: uint32(i), Prio: randValues[i]}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		copy(items, values)
+		prioqueue.PartialSort(items, 10)
+	}
+}
+
+// BenchmarkStdlib_SortSlice_200 tests how fast sort.Slice sorts 200 random
+// items to provide a baseline for the SortItems and PartialSort benchmarks.
+func BenchmarkStdlib_SortSlice_200(b *testing.B) {
+	values := make([]*prioqueue.Item, len(randValues))
+	items := make([]*prioqueue.Item, len(randValues))
+	for i := range values {
+		values[i] = &prioqueue.Item{ID: uint32(i), Prio: randValues[i]}
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		copy(items, values)
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].Prio > items[j].Prio
+		})
+	}
+}
+
+// BenchmarkMinHeap runs the benchmarks of the prioqueuetest package against
+// the MinHeap.
+func BenchmarkMinHeap(b *testing.B) {
+	prioqueuetest.Bench(b, func() prioqueuetest.PriorityQueue {
+		return new(prioqueue.MinHeap)
+	})
+}
+
+// BenchmarkMultiQueue_Parallel tests how fast the MultiQueue is if many
+// goroutines push and pop items concurrently. Each operation of this
+// benchmark is a single push followed by a single pop.
+func BenchmarkMultiQueue_Parallel(b *testing.B) {
+	q := prioqueue.NewMultiQueue(0)
+	for i := 0; i < 10_000; i++ {
+		q.Push(uint32(i), randValues[i%len(randValues)])
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		var i uint32
+		for pb.Next() {
+			q.Push(i, randValues[i%uint32(len(randValues))])
+			q.Pop()
+			i++
+		}
+	})
+}
+
+// BenchmarkMutexMaxHeap_Parallel is the baseline for
+// BenchmarkMultiQueue_Parallel which protects a single MaxHeap with a mutex.
+func BenchmarkMutexMaxHeap_Parallel(b *testing.B) {
+	var mu sync.Mutex
+	q := prioqueue.NewMaxHeap(0)
+	for i := 0; i < 10_000; i++ {
+		q.Push(uint32(i), randValues[i%len(randValues)])
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		var i uint32
+		for pb.Next() {
+			mu.Lock()
+			q.Push(i, randValues[i%uint32(len(randValues))])
+			q.Pop()
+			mu.Unlock()
+			i++
+		}
+	})
+}
+
+// BenchmarkRadixHeap_Push200 tests how fast we can push 200 elements on the
+// RadixHeap. The keys are the random values of the other benchmarks, scaled to
+// 32 bit integers.
+func BenchmarkRadixHeap_Push200(b *testing.B) {
+	h := prioqueue.NewRadixHeap()
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for id := uint32(0); id < 200; id++ {
+			_ = h.Push(id, uint64(randValues[id]*(1<<32)))
+		}
+
+		b.StopTimer()
+		h.Reset()
+		b.StartTimer()
+	}
+}
+
+// BenchmarkRadixHeap_Pop200 tests how long it takes to pop all elements from a
+// RadixHeap which contains 200 random elements.
+func BenchmarkRadixHeap_Pop200(b *testing.B) {
+	h := prioqueue.NewRadixHeap()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		h.Reset()
+		for id := uint32(0); id < 200; id++ {
+			_ = h.Push(id, uint64(randValues[id]*(1<<32)))
+		}
+		b.StartTimer()
+
+		for h.Len() > 0 {
+			h.Pop()
+		}
+	}
+}
+
+// BenchmarkMinHeap_Push200 is the baseline for BenchmarkRadixHeap_Push200.
+func BenchmarkMinHeap_Push200(b *testing.B) {
+	h := prioqueue.NewMinHeap(200)
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for id := uint32(0); id < 200; id++ {
+			h.Push(id, randValues[id])
+		}
+
+		b.StopTimer()
+		h = prioqueue.NewMinHeap(200)
+		b.StartTimer()
+	}
+}
+
+// BenchmarkMinHeap_Pop200 is the baseline for BenchmarkRadixHeap_Pop200.
+func BenchmarkMinHeap_Pop200(b *testing.B) {
+	h := prioqueue.NewMinHeap(len(randValues))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		for id := uint32(0); id < 200; id++ {
+			h.Push(id, randValues[id])
+		}
+		b.StartTimer()
+
+		for h.Len() > 0 {
+			h.Pop()
+		}
+	}
+}
+
+// BenchmarkBucketQueue_Push200 tests how fast we can push 200 elements on the
+// BucketQueue. The priorities are the random values of the other benchmarks,
+// scaled to the 256 priority levels.
+func BenchmarkBucketQueue_Push200(b *testing.B) {
+	q := prioqueue.NewBucketQueue()
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for id := uint32(0); id < 200; id++ {
+			q.Push(id, float32(uint8(randValues[id]*256)))
+		}
+
+		b.StopTimer()
+		q.Reset()
+		b.StartTimer()
+	}
+}
+
+// BenchmarkBucketQueue_Pop200 tests how long it takes to pop all elements from
+// a BucketQueue which contains 200 random elements.
+func BenchmarkBucketQueue_Pop200(b *testing.B) {
+	q := prioqueue.NewBucketQueue()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		for id := uint32(0); id < 200; id++ {
+			q.Push(id, float32(uint8(randValues[id]*256)))
+		}
+		b.StartTimer()
+
+		for q.Len() > 0 {
+			q.Pop()
+		}
+	}
+}
+
+// BenchmarkCalendarQueue runs the benchmarks of the prioqueuetest package
+// against the CalendarQueue.
+func BenchmarkCalendarQueue(b *testing.B) {
+	prioqueuetest.Bench(b, func() prioqueuetest.PriorityQueue {
+		return new(prioqueue.CalendarQueue)
+	})
+}
+
+// BenchmarkCalendarQueue_Hold tests the typical workload of an event
+// simulation, where each popped item is replaced by an item with a slightly
+// larger priority, while the queue contains 10,000 items.
+func BenchmarkCalendarQueue_Hold(b *testing.B) {
+	q := prioqueue.NewCalendarQueue()
+	benchmarkHold(b, q)
+}
+
+// BenchmarkMinHeap_Hold is the baseline for BenchmarkCalendarQueue_Hold.
+func BenchmarkMinHeap_Hold(b *testing.B) {
+	h := prioqueue.NewMinHeap(0)
+	benchmarkHold(b, h)
+}
+
+func benchmarkHold(b *testing.B, q prioqueuetest.PriorityQueue) {
+	rng := rand.New(rand.NewSource(42))
+	for id := uint32(0); id < 10_000; id++ {
+		q.Push(id, rng.Float32())
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		id, now := q.Pop()
+		q.Push(id, now+randValues[i%len(randValues)])
+	}
+}
+
+// BenchmarkVEBQueue_Push200 tests how fast we can push 200 elements on the
+// VEBQueue. The keys are the random values of the other benchmarks, scaled to
+// 32 bit integers.
+func BenchmarkVEBQueue_Push200(b *testing.B) {
+	q := prioqueue.NewVEBQueue()
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for id := uint32(0); id < 200; id++ {
+			q.Push(id, uint32(randValues[id]*(1<<32)))
+		}
+
+		b.StopTimer()
+		q.Reset()
+		b.StartTimer()
+	}
+}
+
+// BenchmarkVEBQueue_Pop200 tests how long it takes to pop all elements from a
+// VEBQueue which contains 200 random elements.
+func BenchmarkVEBQueue_Pop200(b *testing.B) {
+	q := prioqueue.NewVEBQueue()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		for id := uint32(0); id < 200; id++ {
+			q.Push(id, uint32(randValues[id]*(1<<32)))
+		}
+		b.StartTimer()
+
+		for q.Len() > 0 {
+			q.Pop()
+		}
+	}
+}
+
+// BenchmarkMaxHeap_Push200_Observer is like BenchmarkMaxHeap_Push200_Preallocate
+// but the heap counts all operations via an Observer.
+func BenchmarkMaxHeap_Push200_Observer(b *testing.B) {
+	q := prioqueue.NewMaxHeap(200)
+	q.SetObserver(new(prioqueue.Counters), 100)
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for id := uint32(0); id < 200; id++ {
+			q.Push(id, randValues[id])
+		}
+
+		b.StopTimer()
+		q.Reset()
+		b.StartTimer()
+	}
+}
+
+// BenchmarkMaxHeap_Pop200_Observer is like BenchmarkMaxHeap_Pop200 but the
+// heap counts all operations via an Observer.
+func BenchmarkMaxHeap_Pop200_Observer(b *testing.B) {
+	q := prioqueue.NewMaxHeap(len(randValues))
+	q.SetObserver(new(prioqueue.Counters), 100)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		for id := uint32(0); id < 200; id++ {
+			q.Push(id, randValues[id])
+		}
+		b.StartTimer()
+
+		for q.Len() > 0 {
+			q.Pop()
+		}
+	}
+}
+
+// largeHeapSizes are the sizes of the heaps in the benchmarks for large heaps.
+var largeHeapSizes = []int{1 << 10, 1 << 16, 1 << 22}
+
+// BenchmarkBHeap_Hold tests how fast an item can be popped from a BHeap and
+// replaced by a new one for heaps of different sizes. With large heaps, this is
+// dominated by cache misses.
+func BenchmarkBHeap_Hold(b *testing.B) {
+	for _, n := range largeHeapSizes {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			benchmarkLargeHold(b, prioqueue.NewBHeap(n), n)
+		})
+	}
+}
+
+// BenchmarkMaxHeap_Hold is the baseline for BenchmarkBHeap_Hold.
+func BenchmarkMaxHeap_Hold(b *testing.B) {
+	for _, n := range largeHeapSizes {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			benchmarkLargeHold(b, prioqueue.NewMaxHeap(n), n)
+		})
+	}
+}
+
+func benchmarkLargeHold(b *testing.B, q prioqueuetest.PriorityQueue, n int) {
+	rng := rand.New(rand.NewSource(42))
+	for id := 0; id < n; id++ {
+		q.Push(uint32(id), rng.Float32())
+	}
+
+	values := make([]float32, 1<<16)
+	for i := range values {
+		values[i] = rng.Float32()
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		id, _ := q.Pop()
+		q.Push(id, values[i%len(values)])
+	}
+}
+
+// BenchmarkMaxHeap_PushMany200 tests how fast we can push 200 elements on the
+// queue in a single batch. It can be compared to
+// BenchmarkMaxHeap_Push200_Preallocate.
+func BenchmarkMaxHeap_PushMany200(b *testing.B) {
+	items := make([]prioqueue.Item, len(randValues))
+	for i, v := range randValues {
+		items[i] = prioqueue.Item{ID: uint32(i), Prio: v}
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		q := prioqueue.NewMaxHeap(len(items))
+		q.PushMany(items)
+	}
+}
+
+// BenchmarkMaxHeap_PopN200 tests how long it takes to pop all elements from a
+// MaxHeap which contains 200 random elements in a single batch. It can be
+// compared to BenchmarkMaxHeap_Pop200.
+func BenchmarkMaxHeap_PopN200(b *testing.B) {
+	pq := prioqueue.NewMaxHeap(len(randValues))
+	dst := make([]*prioqueue.Item, 0, len(randValues))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		for id := uint32(0); id < 200; id++ {
+			pq.Push(id, randValues[id])
+		}
+		b.StartTimer()
+
+		dst = pq.PopN(len(randValues), dst[:0])
+	}
+}
@@ -0,0 +1,166 @@
+package prioqueue
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// BucketQueue implements a priority queue for 256 discrete priority levels
+// which allows to retrieve the item with the highest priority. It is faster
+// than the MaxHeap if the priorities of all items fall into such a small range
+// because it does not need to compare items with each other.
+//
+// The BucketQueue has the same API as the MaxHeap, so it can be swapped in if
+// all priorities are integers from 0 to 255. Push panics for any other
+// priority since such an item could not be ordered correctly within the FIFO
+// list of a level.
+//
+// The queue keeps one FIFO list of IDs per priority level and a bitmap which
+// marks the levels that contain at least one item. The highest non-empty level
+// is found by counting the leading zeros of the bitmap. Items with equal
+// priority are dequeued in the order in which they were pushed.
+//
+// Time Complexity
+//
+//   Push, Pop and Top happen in constant time.
+type BucketQueue struct {
+	levels [256]bucketFIFO
+	bitmap [4]uint64 // bit i%64 of word i/64 is set if level i is not empty
+	len    int
+}
+
+// bucketFIFO is the FIFO list of IDs of a single priority level. Popped IDs
+// stay in the slice until the space is needed again.
+type bucketFIFO struct {
+	ids  []uint32
+	head int // index of the next ID to pop
+}
+
+// NewBucketQueue returns a new BucketQueue instance. The zero value of a
+// BucketQueue is also ready to use.
+func NewBucketQueue() *BucketQueue {
+	return new(BucketQueue)
+}
+
+// Len returns the amount of elements in the queue.
+func (q *BucketQueue) Len() int {
+	return q.len
+}
+
+// Reset is a fast way to empty the queue. Note that the memory of the FIFO
+// lists is kept and reused by the queue.
+func (q *BucketQueue) Reset() {
+	for i := range q.levels {
+		q.levels[i].ids = q.levels[i].ids[:0]
+		q.levels[i].head = 0
+	}
+	q.bitmap = [4]uint64{}
+	q.len = 0
+}
+
+// Push adds an item with the given ID and priority to the queue. The priority
+// must be an integer from 0 to 255, otherwise Push panics.
+func (q *BucketQueue) Push(id uint32, prio float32) {
+	q.push(id, bucketLevel(prio))
+}
+
+// PopAndPush removes the item with the highest priority and adds the given
+// item to the queue. If the queue is empty, the item is simply pushed. Just
+// like Push, PopAndPush panics if the priority of the item is not an integer
+// from 0 to 255. In this case, no item is removed.
+func (q *BucketQueue) PopAndPush(item *Item) {
+	level := bucketLevel(item.Prio)
+	if q.len > 0 {
+		q.Pop()
+	}
+	q.push(item.ID, level)
+}
+
+// push adds an item with the given ID to the FIFO list of the given level.
+func (q *BucketQueue) push(id uint32, prio uint8) {
+	q.levels[prio].push(id)
+	q.bitmap[prio/64] |= 1 << (prio % 64)
+	q.len++
+}
+
+// Top returns the ID and priority of the item with the highest priority in the
+// queue without removing it. If there are multiple such items, the one that was
+// pushed first is returned.
+func (q *BucketQueue) Top() (id uint32, prio float32) {
+	if q.len == 0 {
+		return 0, 0
+	}
+
+	level := q.highest()
+	l := &q.levels[level]
+	return l.ids[l.head], float32(level)
+}
+
+// Pop removes the item with the highest priority from the queue and returns
+// its ID and priority. If there are multiple such items, the one that was
+// pushed first is removed.
+func (q *BucketQueue) Pop() (id uint32, prio float32) {
+	if q.len == 0 {
+		return 0, 0
+	}
+
+	level := q.highest()
+	l := &q.levels[level]
+	id = l.pop()
+	if l.len() == 0 {
+		q.bitmap[level/64] &^= 1 << (level % 64)
+	}
+
+	q.len--
+	return id, float32(level)
+}
+
+// highest returns the highest priority level which is not empty. The queue
+// must not be empty.
+func (q *BucketQueue) highest() uint8 {
+	i := len(q.bitmap) - 1
+	for q.bitmap[i] == 0 {
+		i--
+	}
+
+	return uint8(i*64 + 63 - bits.LeadingZeros64(q.bitmap[i]))
+}
+
+// push appends an ID to the FIFO list.
+func (f *bucketFIFO) push(id uint32) {
+	if f.head > 0 && len(f.ids) == cap(f.ids) {
+		// Move the remaining IDs to the front instead of growing the slice
+		// while it still contains the space of popped IDs.
+		n := copy(f.ids, f.ids[f.head:])
+		f.ids = f.ids[:n]
+		f.head = 0
+	}
+
+	f.ids = append(f.ids, id)
+}
+
+// pop removes the first ID from the non-empty FIFO list and returns it.
+func (f *bucketFIFO) pop() uint32 {
+	id := f.ids[f.head]
+	f.head++
+	if f.head == len(f.ids) {
+		f.ids = f.ids[:0]
+		f.head = 0
+	}
+
+	return id
+}
+
+// len returns the amount of IDs in the FIFO list.
+func (f *bucketFIFO) len() int {
+	return len(f.ids) - f.head
+}
+
+// bucketLevel returns the level of the given priority. It panics if the
+// priority is not an integer from 0 to 255.
+func bucketLevel(prio float32) uint8 {
+	if !(prio >= 0 && prio <= 255) || prio != float32(uint8(prio)) {
+		panic(fmt.Sprintf("prioqueue: BucketQueue priority %v is not an integer from 0 to 255", prio))
+	}
+	return uint8(prio)
+}
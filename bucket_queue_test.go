@@ -0,0 +1,118 @@
+package prioqueue_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/fgrosse/prioqueue/prioqueuetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucketQueue(t *testing.T) {
+	var q prioqueue.BucketQueue
+
+	id, prio := q.Pop()
+	assert.EqualValues(t, 0, id)
+	assert.EqualValues(t, 0, prio)
+	id, prio = q.Top()
+	assert.EqualValues(t, 0, id)
+	assert.EqualValues(t, 0, prio)
+
+	// The levels span all words of the bitmap.
+	q.Push(1, 0)
+	q.Push(2, 64)
+	q.Push(3, 255)
+	q.Push(4, 63)
+	q.Push(5, 64)
+	q.Push(6, 128)
+	q.Push(7, 0)
+	require.Equal(t, 7, q.Len())
+
+	expected := []struct {
+		id   uint32
+		prio float32
+	}{{3, 255}, {6, 128}, {2, 64}, {5, 64}, {4, 63}, {1, 0}, {7, 0}}
+
+	for _, e := range expected {
+		topID, topPrio := q.Top()
+		id, prio := q.Pop()
+		assert.Equal(t, topID, id)
+		assert.Equal(t, topPrio, prio)
+		assert.Equal(t, e.id, id)
+		assert.Equal(t, e.prio, prio)
+	}
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestBucketQueue_Reset(t *testing.T) {
+	q := prioqueue.NewBucketQueue()
+	for i := 0; i < 100; i++ {
+		q.Push(uint32(i), float32(i))
+	}
+
+	q.Reset()
+	assert.Equal(t, 0, q.Len())
+	id, prio := q.Top()
+	assert.EqualValues(t, 0, id)
+	assert.EqualValues(t, 0, prio)
+
+	q.Push(42, 7)
+	id, prio = q.Pop()
+	assert.EqualValues(t, 42, id)
+	assert.EqualValues(t, 7, prio)
+}
+
+func TestBucketQueue_Random(t *testing.T) {
+	q := prioqueue.NewBucketQueue()
+	model := prioqueue.NewMaxHeap(0)
+	model.SetTieBreakByID(true)
+	rng := rand.New(rand.NewSource(42))
+
+	// The IDs are increasing, so the FIFO order of the queue corresponds to
+	// the ascending ID order of the MaxHeap.
+	for i := 0; i < 100_000; i++ {
+		if rng.Intn(2) == 0 {
+			prio := float32(rng.Intn(8) * 32)
+			q.Push(uint32(i), prio)
+			model.Push(uint32(i), prio)
+			continue
+		}
+
+		id, prio := q.Pop()
+		expectedID, expectedPrio := model.Pop()
+		require.Equal(t, expectedID, id)
+		require.Equal(t, expectedPrio, prio)
+		require.Equal(t, model.Len(), q.Len())
+	}
+}
+
+// TestBucketQueue_Model checks the queue against the model of the prioqueuetest
+// package. The queue cannot be tested with prioqueuetest.Run since the suite
+// uses arbitrary priorities, so the operations use valid levels only.
+func TestBucketQueue_Model(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	data := make([]byte, 3*10_000)
+	rng.Read(data)
+
+	ops := prioqueuetest.DecodeOps(data)
+	for i := range ops {
+		ops[i].Item.Prio = float32(rng.Intn(8) * 32)
+	}
+
+	prioqueuetest.Check(t, prioqueue.NewBucketQueue(), prioqueuetest.MaxFirst, ops)
+}
+
+func TestBucketQueue_InvalidPriority(t *testing.T) {
+	q := prioqueue.NewBucketQueue()
+	q.Push(1, 42)
+
+	for _, prio := range []float32{-1, 256, 1.5, float32(math.NaN())} {
+		assert.Panics(t, func() { q.Push(2, prio) }, "priority %v", prio)
+		assert.Panics(t, func() { q.PopAndPush(&prioqueue.Item{ID: 2, Prio: prio}) }, "priority %v", prio)
+	}
+
+	assert.Equal(t, 1, q.Len(), "invalid items should neither be pushed nor replace the top item")
+}
@@ -0,0 +1,272 @@
+package prioqueue
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// minCalendarBuckets is the number of buckets below which a CalendarQueue
+	// never shrinks.
+	minCalendarBuckets = 2
+
+	// calendarSampleSize is the number of items which are used to estimate
+	// the width of a bucket when a CalendarQueue is resized.
+	calendarSampleSize = 25
+
+	// calendarBucketCap is the initial capacity of each bucket after a
+	// CalendarQueue has been resized. On average, a bucket holds one or two
+	// items.
+	calendarBucketCap = 4
+
+	// maxCalendarDay limits the day numbers so they can be incremented
+	// without overflowing even if the bucket width is tiny compared to the
+	// priorities.
+	maxCalendarDay = 1 << 62
+)
+
+// CalendarQueue implements a priority queue which allows to retrieve the item
+// with the lowest priority. It is well suited for time-like priorities, as
+// they are used in event simulations, where most new items have a priority
+// slightly larger than the item that was popped last.
+//
+// The queue works like a desk calendar. The priority axis is divided into
+// days of equal width and each day is assigned to one of the buckets in a
+// round-robin fashion, so every bucket holds the days of many "years". Pop
+// looks at the bucket of the current day and moves on to the next bucket until
+// it finds an item that belongs to the current year. If no such item is found
+// within a full year, the minimum is searched directly among all buckets.
+// The number of buckets and the width of a day are adjusted whenever the queue
+// grows or shrinks considerably, such that each bucket contains only a few
+// items.
+//
+// The priority queue has the following properties:
+//   - items with low priority are dequeued before elements with higher priority
+//   - items with equal priority are dequeued in ascending order of their IDs
+//
+// Time Complexity
+//
+//   Push, Pop and Top take amortized constant time if the priorities are
+//   evenly distributed. In the worst case they take O(n).
+type CalendarQueue struct {
+	buckets [][]Item // each bucket is sorted such that its minimum is last
+	width   float64  // the width of a day on the priority axis
+	day     int64    // the current day which is not after the day of any item
+	len     int
+}
+
+// NewCalendarQueue returns a new CalendarQueue instance. The zero value of a
+// CalendarQueue is also ready to use.
+func NewCalendarQueue() *CalendarQueue {
+	return new(CalendarQueue)
+}
+
+// Len returns the amount of elements in the queue.
+func (q *CalendarQueue) Len() int {
+	return q.len
+}
+
+// Reset is a fast way to empty the queue. Note that the buckets will still be
+// used by the queue which means that this function will not free up any
+// memory.
+func (q *CalendarQueue) Reset() {
+	for i := range q.buckets {
+		q.buckets[i] = q.buckets[i][:0]
+	}
+	q.day = 0
+	q.len = 0
+}
+
+// Push adds an item with the given ID and priority to the queue.
+func (q *CalendarQueue) Push(id uint32, prio float32) {
+	if q.buckets == nil {
+		q.buckets = make([][]Item, minCalendarBuckets)
+		q.width = 1
+	}
+
+	q.insert(Item{ID: id, Prio: prio})
+	q.len++
+
+	if q.len > 2*len(q.buckets) {
+		q.resize(2 * len(q.buckets))
+	}
+}
+
+// Top returns the ID and priority of the item with the lowest priority value in
+// the queue without removing it.
+func (q *CalendarQueue) Top() (id uint32, prio float32) {
+	if q.len == 0 {
+		return 0, 0
+	}
+
+	b := q.buckets[q.next()]
+	item := b[len(b)-1]
+	return item.ID, item.Prio
+}
+
+// Pop removes the item with the lowest priority value from the queue and
+// returns its ID and priority.
+func (q *CalendarQueue) Pop() (id uint32, prio float32) {
+	if q.len == 0 {
+		return 0, 0
+	}
+
+	item := q.remove(q.next())
+	if q.len < len(q.buckets)/2 && len(q.buckets) > minCalendarBuckets {
+		q.resize(len(q.buckets) / 2)
+	}
+
+	return item.ID, item.Prio
+}
+
+// PopAndPush removes the item with the lowest priority value and adds a new
+// value to the queue. If the queue is empty, the item is simply pushed.
+func (q *CalendarQueue) PopAndPush(item *Item) {
+	q.Pop()
+	q.Push(item.ID, item.Prio)
+}
+
+// insert adds an item to its bucket without changing the length of the queue.
+func (q *CalendarQueue) insert(item Item) {
+	d := q.dayOf(item.Prio)
+	if d < q.day {
+		q.day = d
+	}
+
+	i := q.bucketOf(d)
+	b := q.buckets[i]
+	j := sort.Search(len(b), func(j int) bool {
+		return minFirst(&b[j], &item)
+	})
+
+	b = append(b, Item{})
+	copy(b[j+1:], b[j:])
+	b[j] = item
+	q.buckets[i] = b
+}
+
+// remove removes the last item of the bucket at index i.
+func (q *CalendarQueue) remove(i int) Item {
+	b := q.buckets[i]
+	item := b[len(b)-1]
+	q.buckets[i] = b[:len(b)-1]
+	q.len--
+	return item
+}
+
+// next advances the current day to the day of the item with the lowest
+// priority and returns the index of its bucket. The queue must not be empty.
+func (q *CalendarQueue) next() int {
+	// Since no item is before the current day, the first bucket whose
+	// minimum belongs to the day that is currently looked at contains the
+	// minimum of the whole queue.
+	for d := q.day; d < q.day+int64(len(q.buckets)); d++ {
+		i := q.bucketOf(d)
+		b := q.buckets[i]
+		if len(b) > 0 && q.dayOf(b[len(b)-1].Prio) == d {
+			q.day = d
+			return i
+		}
+	}
+
+	// There is no item within the next year, so we search the minimum
+	// directly and jump to its day.
+	min := -1
+	for i, b := range q.buckets {
+		if len(b) == 0 {
+			continue
+		}
+		if min < 0 || minFirst(&b[len(b)-1], &q.buckets[min][len(q.buckets[min])-1]) {
+			min = i
+		}
+	}
+
+	b := q.buckets[min]
+	q.day = q.dayOf(b[len(b)-1].Prio)
+	return min
+}
+
+// resize redistributes all items into the given number of buckets. The width
+// of a day is estimated from the gaps between the items with the lowest
+// priorities, such that the next items will be spread over a few buckets.
+func (q *CalendarQueue) resize(n int) {
+	sample := make([]Item, 0, calendarSampleSize)
+	for len(sample) < cap(sample) && q.len > 0 {
+		sample = append(sample, q.remove(q.next()))
+	}
+
+	if w := calendarWidth(sample); w > 0 {
+		q.width = w
+	}
+
+	items := make([]Item, 0, len(sample)+q.len)
+	items = append(items, sample...)
+	for _, b := range q.buckets {
+		items = append(items, b...)
+	}
+
+	// All buckets share a single backing array with room for a few items per
+	// bucket. Buckets which grow larger are moved to their own array by
+	// append.
+	block := make([]Item, n*calendarBucketCap)
+	q.buckets = make([][]Item, n)
+	for i := range q.buckets {
+		q.buckets[i] = block[i*calendarBucketCap : i*calendarBucketCap : (i+1)*calendarBucketCap]
+	}
+
+	q.day = maxCalendarDay
+	q.len = len(items)
+	for _, item := range items {
+		q.insert(item)
+	}
+}
+
+// dayOf returns the day of the given priority.
+func (q *CalendarQueue) dayOf(prio float32) int64 {
+	d := math.Floor(float64(prio) / q.width)
+	switch {
+	case !(d > -maxCalendarDay): // also catches NaN
+		return -maxCalendarDay
+	case d > maxCalendarDay:
+		return maxCalendarDay
+	default:
+		return int64(d)
+	}
+}
+
+// bucketOf returns the index of the bucket which holds the items of day d.
+func (q *CalendarQueue) bucketOf(d int64) int {
+	i := int(d % int64(len(q.buckets)))
+	if i < 0 {
+		i += len(q.buckets)
+	}
+	return i
+}
+
+// calendarWidth estimates the width of a day from the sorted sample. The width
+// is three times the average gap between the items where unusually large gaps
+// are ignored. It returns 0 if the width cannot be estimated.
+func calendarWidth(sample []Item) float64 {
+	if len(sample) < 2 {
+		return 0
+	}
+
+	first, last := float64(sample[0].Prio), float64(sample[len(sample)-1].Prio)
+	avg := (last - first) / float64(len(sample)-1)
+
+	var sum float64
+	var n int
+	for i := 1; i < len(sample); i++ {
+		gap := float64(sample[i].Prio) - float64(sample[i-1].Prio)
+		if gap <= 2*avg {
+			sum += gap
+			n++
+		}
+	}
+
+	w := 3 * sum / float64(n)
+	if math.IsNaN(w) || math.IsInf(w, 0) {
+		return 0
+	}
+	return w
+}
@@ -0,0 +1,82 @@
+package prioqueue_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/fgrosse/prioqueue/prioqueuetest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarQueue(t *testing.T) {
+	prioqueuetest.Run(t, func() prioqueuetest.PriorityQueue {
+		return prioqueue.NewCalendarQueue()
+	}, prioqueuetest.MinFirst)
+}
+
+// TestCalendarQueue_Hold tests the queue with the typical workload of an event
+// simulation in which each popped event schedules a new event in the future.
+func TestCalendarQueue_Hold(t *testing.T) {
+	q := prioqueue.NewCalendarQueue()
+	model := prioqueue.NewMinHeap(0)
+	model.SetTieBreakByID(true)
+	rng := rand.New(rand.NewSource(42))
+
+	id := uint32(0)
+	for ; id < 1000; id++ {
+		prio := rng.Float32() * 100
+		q.Push(id, prio)
+		model.Push(id, prio)
+	}
+
+	for i := 0; i < 100_000; i++ {
+		gotID, now := q.Pop()
+		expectedID, expectedPrio := model.Pop()
+		require.Equal(t, expectedID, gotID)
+		require.Equal(t, expectedPrio, now)
+
+		// The queue alternately grows and shrinks by 1000 items, which
+		// changes the number of buckets.
+		pushes := 0
+		if i/1000%2 == 0 {
+			pushes = 2
+		}
+		for j := 0; j < pushes; j++ {
+			prio := now + rng.Float32()*100
+			q.Push(id, prio)
+			model.Push(id, prio)
+			id++
+		}
+		require.Equal(t, model.Len(), q.Len())
+	}
+}
+
+// TestCalendarQueue_Spread tests the queue with priorities of very different
+// magnitudes, including priorities that are far away from all other items.
+func TestCalendarQueue_Spread(t *testing.T) {
+	q := prioqueue.NewCalendarQueue()
+	rng := rand.New(rand.NewSource(42))
+
+	var ops []prioqueuetest.Op
+	for i := 0; i < 10_000; i++ {
+		prio := float32(rng.NormFloat64()) * 1e-3
+		switch rng.Intn(10) {
+		case 0:
+			prio = 1e30
+		case 1:
+			prio = -1e30
+		}
+
+		kind := prioqueuetest.OpPush
+		if rng.Intn(3) == 0 {
+			kind = prioqueuetest.OpPop
+		}
+		ops = append(ops, prioqueuetest.Op{
+			Kind: kind,
+			Item: prioqueue.Item{ID: uint32(i), Prio: prio},
+		})
+	}
+
+	prioqueuetest.Check(t, q, prioqueuetest.MinFirst, ops)
+}
@@ -0,0 +1,12 @@
+//go:build prioqueue_countcmp
+// +build prioqueue_countcmp
+
+package prioqueue
+
+// countComparisons enables counting the comparisons between items, so the
+// benchmarks can report them. Use the prioqueue_countcmp build tag to enable
+// it.
+const countComparisons = true
+
+// comparisons is the number of comparisons between items so far.
+var comparisons uint64
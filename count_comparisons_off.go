@@ -0,0 +1,11 @@
+//go:build !prioqueue_countcmp
+// +build !prioqueue_countcmp
+
+package prioqueue
+
+// countComparisons is false unless the prioqueue_countcmp build tag is used, so
+// the comparisons between items are not counted.
+const countComparisons = false
+
+// comparisons is never incremented without the prioqueue_countcmp build tag.
+var comparisons uint64
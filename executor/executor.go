@@ -0,0 +1,187 @@
+// Package executor implements a worker pool which runs tasks in the order of
+// their priority.
+package executor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrShutdown is returned by Submit if the Executor has been shut down.
+var ErrShutdown = errors.New("executor: shut down")
+
+// Executor runs submitted tasks on a fixed amount of worker goroutines. If all
+// workers are busy, tasks wait in a priority queue and the task with the
+// highest priority runs next. Tasks with equal priority run in the order in
+// which they were submitted.
+//
+// If a task panics, the panic is recovered so the worker can continue with the
+// next task.
+type Executor struct {
+	queue  *taskQueue
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu           sync.Mutex
+	stats        Stats
+	panicHandler func(recovered interface{})
+}
+
+// Stats contains metrics about the tasks of an Executor.
+type Stats struct {
+	Queued    int    // amount of tasks which wait to be run
+	Running   int    // amount of tasks which are currently running
+	Submitted uint64 // amount of tasks which have been submitted
+	Completed uint64 // amount of tasks which finished, including panics
+	Panicked  uint64 // amount of tasks which panicked
+
+	TotalWait time.Duration // sum of the time each started task was queued
+	MaxWait   time.Duration // longest time a started task was queued
+}
+
+// MeanWait returns the average time the started tasks were queued.
+func (s Stats) MeanWait() time.Duration {
+	started := s.Completed + uint64(s.Running)
+	if started == 0 {
+		return 0
+	}
+	return s.TotalWait / time.Duration(started)
+}
+
+// New returns a new Executor which runs tasks on the given amount of workers.
+// If workers is less than 1, a single worker is used.
+func New(workers int) *Executor {
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &Executor{
+		queue:  newTaskQueue(),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	e.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go e.work()
+	}
+
+	return e
+}
+
+// SetPanicHandler sets a function which is called with the recovered value
+// whenever a task panics.
+func (e *Executor) SetPanicHandler(fn func(recovered interface{})) {
+	e.mu.Lock()
+	e.panicHandler = fn
+	e.mu.Unlock()
+}
+
+// Submit adds a task with the given priority. The task is called with a
+// context which is cancelled if Shutdown gives up waiting for the running
+// tasks. Submit returns ErrShutdown if Shutdown has been called.
+func (e *Executor) Submit(prio float32, fn func(ctx context.Context)) error {
+	// The task is pushed while holding the lock, so no worker can count it as
+	// running or completed and Stats cannot see it in the queue before it has
+	// been counted as submitted.
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	err := e.queue.push(prio, &task{fn: fn, enqueued: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	e.stats.Submitted++
+	return nil
+}
+
+// Shutdown stops accepting new tasks and waits until all submitted tasks have
+// finished. If the context is done before that, the tasks which are still
+// queued are dropped, the context passed to the running tasks is cancelled and
+// Shutdown returns the error of the context.
+func (e *Executor) Shutdown(ctx context.Context) error {
+	e.queue.close()
+
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		e.cancel()
+		return nil
+	case <-ctx.Done():
+		e.queue.clear()
+		e.cancel()
+		return ctx.Err()
+	}
+}
+
+// Stats returns the current metrics of the Executor.
+func (e *Executor) Stats() Stats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s := e.stats
+	s.Queued = e.queue.len()
+	return s
+}
+
+// work runs tasks until the queue is closed and empty.
+func (e *Executor) work() {
+	defer e.wg.Done()
+
+	for {
+		t, ok := e.queue.pop()
+		if !ok {
+			return
+		}
+
+		wait := time.Since(t.enqueued)
+		e.mu.Lock()
+		e.stats.Running++
+		e.stats.TotalWait += wait
+		if wait > e.stats.MaxWait {
+			e.stats.MaxWait = wait
+		}
+		e.mu.Unlock()
+
+		panicked := e.run(t)
+
+		e.mu.Lock()
+		e.stats.Running--
+		e.stats.Completed++
+		if panicked {
+			e.stats.Panicked++
+		}
+		e.mu.Unlock()
+	}
+}
+
+// run runs a single task and recovers if it panics.
+func (e *Executor) run(t *task) (panicked bool) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		panicked = true
+		e.mu.Lock()
+		handler := e.panicHandler
+		e.mu.Unlock()
+		if handler != nil {
+			handler(r)
+		}
+	}()
+
+	t.fn(e.ctx)
+	return false
+}
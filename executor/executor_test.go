@@ -0,0 +1,176 @@
+package executor_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fgrosse/prioqueue/executor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutor_PriorityOrder(t *testing.T) {
+	e := executor.New(1)
+
+	// Block the only worker until all tasks have been submitted.
+	gate := make(chan struct{})
+	require.NoError(t, e.Submit(0, func(context.Context) { <-gate }))
+	require.Eventually(t, func() bool { return e.Stats().Running == 1 }, time.Second, time.Millisecond)
+
+	var (
+		mu    sync.Mutex
+		order []int
+	)
+	for i, prio := range []float32{1, 5, 3, 5, 2} {
+		i := i
+		require.NoError(t, e.Submit(prio, func(context.Context) {
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+		}))
+	}
+
+	assert.Equal(t, 5, e.Stats().Queued)
+	close(gate)
+	require.NoError(t, e.Shutdown(context.Background()))
+
+	assert.Equal(t, []int{1, 3, 2, 4, 0}, order)
+}
+
+func TestExecutor_Workers(t *testing.T) {
+	const workers = 4
+	e := executor.New(workers)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	release := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		require.NoError(t, e.Submit(1, func(context.Context) {
+			wg.Done()
+			<-release
+		}))
+	}
+
+	// All tasks must run concurrently, otherwise this would block forever.
+	wg.Wait()
+	assert.Equal(t, workers, e.Stats().Running)
+
+	close(release)
+	require.NoError(t, e.Shutdown(context.Background()))
+}
+
+func TestExecutor_Panic(t *testing.T) {
+	e := executor.New(1)
+
+	recovered := make(chan interface{}, 1)
+	e.SetPanicHandler(func(r interface{}) { recovered <- r })
+
+	var ran bool
+	require.NoError(t, e.Submit(2, func(context.Context) { panic("boom") }))
+	require.NoError(t, e.Submit(1, func(context.Context) { ran = true }))
+	require.NoError(t, e.Shutdown(context.Background()))
+
+	assert.Equal(t, "boom", <-recovered)
+	assert.True(t, ran, "worker should continue after a panic")
+
+	stats := e.Stats()
+	assert.EqualValues(t, 2, stats.Submitted)
+	assert.EqualValues(t, 2, stats.Completed)
+	assert.EqualValues(t, 1, stats.Panicked)
+}
+
+func TestExecutor_Shutdown(t *testing.T) {
+	e := executor.New(2)
+
+	var (
+		mu  sync.Mutex
+		ran int
+	)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, e.Submit(float32(i), func(context.Context) {
+			mu.Lock()
+			ran++
+			mu.Unlock()
+		}))
+	}
+
+	require.NoError(t, e.Shutdown(context.Background()))
+	assert.Equal(t, 100, ran, "Shutdown should wait for all queued tasks")
+	assert.Equal(t, executor.ErrShutdown, e.Submit(1, func(context.Context) {}))
+
+	stats := e.Stats()
+	assert.EqualValues(t, 100, stats.Submitted)
+	assert.EqualValues(t, 100, stats.Completed)
+	assert.Zero(t, stats.Queued)
+	assert.Zero(t, stats.Running)
+}
+
+func TestExecutor_StatsConsistent(t *testing.T) {
+	e := executor.New(4)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10_000; i++ {
+			_ = e.Submit(1, func(context.Context) {})
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			require.NoError(t, e.Shutdown(context.Background()))
+			return
+		default:
+		}
+
+		// Tasks which have been popped but not started yet are in none of
+		// the other counters, so their sum may only be smaller.
+		s := e.Stats()
+		counted := s.Completed + uint64(s.Running) + uint64(s.Queued)
+		if counted > s.Submitted {
+			t.Fatalf("Stats count %d tasks but only %d were submitted", counted, s.Submitted)
+		}
+	}
+}
+
+func TestExecutor_ShutdownTimeout(t *testing.T) {
+	e := executor.New(1)
+
+	cancelled := make(chan struct{})
+	require.NoError(t, e.Submit(2, func(ctx context.Context) {
+		<-ctx.Done()
+		close(cancelled)
+	}))
+
+	var ranQueued bool
+	require.NoError(t, e.Submit(1, func(context.Context) { ranQueued = true }))
+	require.Eventually(t, func() bool { return e.Stats().Running == 1 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, e.Shutdown(ctx))
+	<-cancelled // the running task must see the cancelled context
+	assert.Zero(t, e.Stats().Queued, "queued tasks should be dropped")
+	assert.False(t, ranQueued)
+}
+
+func TestExecutor_WaitStats(t *testing.T) {
+	e := executor.New(1)
+
+	gate := make(chan struct{})
+	require.NoError(t, e.Submit(1, func(context.Context) { <-gate }))
+	require.NoError(t, e.Submit(1, func(context.Context) {}))
+
+	time.Sleep(20 * time.Millisecond)
+	close(gate)
+	require.NoError(t, e.Shutdown(context.Background()))
+
+	stats := e.Stats()
+	assert.GreaterOrEqual(t, int64(stats.MaxWait), int64(20*time.Millisecond))
+	assert.GreaterOrEqual(t, int64(stats.TotalWait), int64(stats.MaxWait))
+	assert.Equal(t, stats.TotalWait/2, stats.MeanWait())
+}
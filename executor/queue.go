@@ -0,0 +1,129 @@
+package executor
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/fgrosse/prioqueue"
+)
+
+// task is a function which has been submitted to the Executor.
+type task struct {
+	fn       func(ctx context.Context)
+	enqueued time.Time
+}
+
+// taskQueue is a concurrency-safe priority queue of tasks which is backed by a
+// prioqueue.MaxHeap. The tasks are stored in a map and the heap only contains
+// their IDs. Since IDs are assigned sequentially and the heap breaks ties by
+// ID, tasks with equal priority are dequeued in the order in which they were
+// pushed. Before the IDs overflow, the queued tasks are renumbered.
+type taskQueue struct {
+	mu     sync.Mutex
+	cond   *sync.Cond // signaled when a task is pushed or the queue is closed
+	heap   prioqueue.MaxHeap
+	tasks  map[uint32]*task
+	nextID uint32
+	closed bool
+}
+
+func newTaskQueue() *taskQueue {
+	q := &taskQueue{tasks: map[uint32]*task{}}
+	q.heap.SetTieBreakByID(true)
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push adds a task to the queue. It returns ErrShutdown if the queue has been
+// closed.
+func (q *taskQueue) push(prio float32, t *task) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return ErrShutdown
+	}
+
+	if q.nextID == math.MaxUint32 {
+		q.renumber()
+	}
+
+	id := q.nextID
+	q.nextID++
+	q.tasks[id] = t
+	q.heap.Push(id, prio)
+	q.cond.Signal()
+	return nil
+}
+
+// pop removes the task with the highest priority from the queue. If the queue
+// is empty, pop blocks until a task is pushed or the queue is closed. It
+// returns false if the queue is closed and empty.
+func (q *taskQueue) pop() (*task, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for q.heap.Len() == 0 {
+		if q.closed {
+			return nil, false
+		}
+		q.cond.Wait()
+	}
+
+	item := q.heap.PopItem()
+	t := q.tasks[item.ID]
+	delete(q.tasks, item.ID)
+	q.heap.Release(item)
+	return t, true
+}
+
+// renumber assigns new IDs to all queued tasks, starting at 0, before the IDs
+// overflow. The relative order of the tasks is preserved since they are popped
+// from the heap in order.
+func (q *taskQueue) renumber() {
+	var heap prioqueue.MaxHeap
+	heap.SetTieBreakByID(true)
+	tasks := make(map[uint32]*task, len(q.tasks))
+
+	var id uint32
+	for q.heap.Len() > 0 {
+		item := q.heap.PopItem()
+		tasks[id] = q.tasks[item.ID]
+		item.ID = id
+		heap.PushItem(item)
+		id++
+	}
+
+	q.heap = heap
+	q.tasks = tasks
+	q.nextID = id
+}
+
+// len returns the amount of tasks in the queue.
+func (q *taskQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.heap.Len()
+}
+
+// close makes all future calls to push fail. Tasks which are already in the
+// queue can still be popped.
+func (q *taskQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// clear removes all tasks from the queue and returns how many there were.
+func (q *taskQueue) clear() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n := q.heap.Len()
+	q.heap.Reset()
+	q.tasks = map[uint32]*task{}
+	return n
+}
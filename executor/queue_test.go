@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskQueue_Renumber(t *testing.T) {
+	q := newTaskQueue()
+	q.nextID = math.MaxUint32 - 2
+
+	// Each task records its number when it is run.
+	var order []int
+	push := func(prio float32, n int) {
+		fn := func(context.Context) { order = append(order, n) }
+		assert.NoError(t, q.push(prio, &task{fn: fn}))
+	}
+
+	for n := 0; n < 5; n++ {
+		push(1, n)
+	}
+	push(2, 5)
+	push(1, 6)
+
+	assert.Equal(t, 7, q.len())
+	assert.Less(t, q.nextID, uint32(10), "the IDs should have been renumbered")
+
+	for q.len() > 0 {
+		tk, ok := q.pop()
+		assert.True(t, ok)
+		tk.fn(context.Background())
+	}
+
+	assert.Equal(t, []int{5, 0, 1, 2, 3, 4, 6}, order, "tasks with equal priority should run in FIFO order")
+}
@@ -0,0 +1,8 @@
+package prioqueue
+
+// Comparisons returns the number of comparisons between items so far. The
+// comparisons are only counted if the tests are built with the
+// prioqueue_countcmp build tag, which is reported by the second return value.
+func Comparisons() (n uint64, ok bool) {
+	return comparisons, countComparisons
+}
@@ -0,0 +1,36 @@
+package external_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/fgrosse/prioqueue/external"
+)
+
+// BenchmarkQueue_PushPop tests how fast items can be pushed into a queue and
+// popped again if most of them have been spilled to disk. Each operation of
+// this benchmark is a single push and a single pop.
+func BenchmarkQueue_PushPop(b *testing.B) {
+	rng := rand.New(rand.NewSource(42))
+	values := make([]float32, b.N)
+	for i := range values {
+		values[i] = rng.Float32()
+	}
+
+	q := external.New(external.Options{MaxItems: 1 << 12, TempDir: b.TempDir()})
+	defer q.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i, v := range values {
+		if err := q.Push(uint32(i), v); err != nil {
+			b.Fatal(err)
+		}
+	}
+	for q.Len() > 0 {
+		if _, _, err := q.Pop(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Package external implements a priority queue which can hold more items than
+// fit into memory by spilling them to temporary files on disk.
+package external
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+
+	"github.com/fgrosse/prioqueue"
+)
+
+// DefaultMaxItems is the amount of items which are kept in memory if no other
+// budget is configured via the Options.
+const DefaultMaxItems = 1 << 20
+
+// recordSize is the size of an item in a run file. Each record contains the
+// ID and the bits of the priority as little endian uint32 values.
+const recordSize = 8
+
+// bufferSize is the size of the buffers which are used to read and write runs.
+const bufferSize = 64 << 10
+
+// Options configure a Queue.
+type Options struct {
+	// MaxItems is the amount of items which are buffered in memory before
+	// they are written to disk. If it is zero or negative, DefaultMaxItems
+	// is used. Each run keeps its file open until all of its items have
+	// been popped, so the budget should be large enough to keep the number
+	// of runs below the limit of open files of the process.
+	MaxItems int
+
+	// TempDir is the directory in which the temporary files are created. If
+	// it is empty, the default directory for temporary files is used (see
+	// os.TempDir).
+	TempDir string
+}
+
+// Queue implements a priority queue which allows to retrieve the item with the
+// lowest priority. New items are pushed into a MinHeap in memory. As soon as it
+// contains as many items as the memory budget allows, its items are sorted and
+// written to a temporary file. Each of these files is called a run.
+//
+// On Pop, the first item of each run is compared to the top of the MinHeap, so
+// the runs are merged lazily while the queue is drained. Only a small buffer
+// of each run is held in memory. The runs are kept in a second MinHeap which
+// is ordered by the priority of their first item.
+//
+// Items with equal priority are returned in an unspecified order.
+//
+// A Queue is not safe for concurrent use by multiple goroutines. It must be
+// closed in order to remove its temporary files.
+type Queue struct {
+	opts Options
+
+	buf     *prioqueue.MinHeap
+	scratch []*prioqueue.Item // used to sort the buffer before it is spilled
+
+	runs  []*run             // indexed by the IDs in heads
+	heads *prioqueue.MinHeap // the first item of each run that is not exhausted
+	len   int
+}
+
+// run is a sorted file of items.
+type run struct {
+	file   *os.File
+	r      *bufio.Reader
+	head   prioqueue.Item // the next item of the run
+	record [recordSize]byte
+}
+
+// New returns a new empty Queue.
+func New(opts Options) *Queue {
+	if opts.MaxItems <= 0 {
+		opts.MaxItems = DefaultMaxItems
+	}
+
+	return &Queue{
+		opts:  opts,
+		buf:   prioqueue.NewMinHeap(0),
+		heads: prioqueue.NewMinHeap(0),
+	}
+}
+
+// Len returns the amount of items in the queue, including the items on disk.
+func (q *Queue) Len() int {
+	return q.len
+}
+
+// Push adds an item with the given ID and priority to the queue. If the memory
+// budget is exhausted, all buffered items are written to a new temporary file.
+// If this fails, the error is returned and the item is not added.
+func (q *Queue) Push(id uint32, prio float32) error {
+	if q.buf.Len() >= q.opts.MaxItems {
+		if err := q.spill(); err != nil {
+			return err
+		}
+	}
+
+	q.buf.Push(id, prio)
+	q.len++
+	return nil
+}
+
+// Top returns the ID and priority of the item with the lowest priority value in
+// the queue without removing it.
+func (q *Queue) Top() (id uint32, prio float32) {
+	if item, _ := q.top(); item != nil {
+		return item.ID, item.Prio
+	}
+	return 0, 0
+}
+
+// Pop removes the item with the lowest priority value from the queue and
+// returns its ID and priority. If the queue is empty, prioqueue.ErrEmpty is
+// returned. If the item is read from a run, the next item of this run is read
+// from disk. If this fails, the popped item is returned together with the
+// error and the queue should be closed since it may have lost items.
+func (q *Queue) Pop() (id uint32, prio float32, err error) {
+	item, r := q.top()
+	if item == nil {
+		return 0, 0, prioqueue.ErrEmpty
+	}
+
+	id, prio = item.ID, item.Prio
+	q.len--
+
+	if r == nil {
+		q.buf.Release(q.buf.PopItem())
+		return id, prio, nil
+	}
+
+	err = q.advance(r)
+	return id, prio, err
+}
+
+// Close removes all temporary files and empties the queue.
+func (q *Queue) Close() error {
+	var err error
+	for _, r := range q.runs {
+		if r == nil {
+			continue
+		}
+		if cerr := r.close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+
+	q.runs = nil
+	q.heads.Reset()
+	q.buf.Reset()
+	q.len = 0
+	return err
+}
+
+// top returns the item with the lowest priority. If it is the head of a run,
+// the run is returned as well.
+func (q *Queue) top() (*prioqueue.Item, *run) {
+	item := q.buf.TopItem()
+	head := q.heads.TopItem()
+	if head == nil {
+		return item, nil
+	}
+
+	r := q.runs[head.ID]
+	if item == nil || r.head.Prio < item.Prio {
+		return &r.head, r
+	}
+
+	return item, nil
+}
+
+// advance reads the next item of the run which is at the top of the heads.
+// Exhausted runs are removed.
+func (q *Queue) advance(r *run) error {
+	ok, err := r.next()
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		// Replace the root by itself with the priority of the new head, so
+		// we do not need to allocate a new item.
+		top := q.heads.TopItem()
+		top.Prio = r.head.Prio
+		q.heads.PopAndPush(top)
+		return nil
+	}
+
+	id, _ := q.heads.Pop()
+	q.runs[id] = nil
+	if q.heads.Len() == 0 {
+		q.runs = q.runs[:0]
+	}
+
+	return r.close()
+}
+
+// spill writes all buffered items as a new run to a temporary file and empties
+// the buffer.
+func (q *Queue) spill() error {
+	items := append(q.scratch[:0], q.buf.Items()...)
+	prioqueue.SortItems(items, false)
+	q.scratch = items
+
+	r, err := writeRun(q.opts.TempDir, items)
+	if err != nil {
+		return err
+	}
+
+	q.heads.Push(uint32(len(q.runs)), r.head.Prio)
+	q.runs = append(q.runs, r)
+
+	q.buf.Reset()
+	for i, item := range items {
+		q.buf.Release(item)
+		items[i] = nil
+	}
+
+	return nil
+}
+
+// writeRun writes the sorted items into a new temporary file in the given
+// directory and returns the run positioned at its first item.
+func writeRun(dir string, items []*prioqueue.Item) (*run, error) {
+	f, err := os.CreateTemp(dir, "prioqueue-run-*")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &run{file: f}
+	w := bufio.NewWriterSize(f, bufferSize)
+	for _, item := range items {
+		binary.LittleEndian.PutUint32(r.record[0:4], item.ID)
+		binary.LittleEndian.PutUint32(r.record[4:8], math.Float32bits(item.Prio))
+		if _, err = w.Write(r.record[:]); err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		_ = r.close()
+		return nil, err
+	}
+
+	r.r = bufio.NewReaderSize(f, bufferSize)
+	if ok, err := r.next(); err != nil || !ok {
+		_ = r.close()
+		if err == nil {
+			err = errors.New("external: run is empty")
+		}
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// next reads the next item of the run into its head. It returns false if the
+// run is exhausted.
+func (r *run) next() (bool, error) {
+	_, err := io.ReadFull(r.r, r.record[:])
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	r.head.ID = binary.LittleEndian.Uint32(r.record[0:4])
+	r.head.Prio = math.Float32frombits(binary.LittleEndian.Uint32(r.record[4:8]))
+	return true, nil
+}
+
+// close closes and removes the file of the run.
+func (r *run) close() error {
+	err := r.file.Close()
+	if rerr := os.Remove(r.file.Name()); rerr != nil && err == nil {
+		err = rerr
+	}
+	return err
+}
@@ -0,0 +1,142 @@
+package external_test
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/fgrosse/prioqueue/external"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue_Spill(t *testing.T) {
+	dir := t.TempDir()
+	q := external.New(external.Options{MaxItems: 3, TempDir: dir})
+	defer q.Close()
+
+	id, prio, err := q.Pop()
+	assert.Equal(t, prioqueue.ErrEmpty, err)
+	assert.EqualValues(t, 0, id)
+	assert.EqualValues(t, 0, prio)
+
+	rng := rand.New(rand.NewSource(42))
+	prios := make([]float32, 1000)
+	for i := range prios {
+		prios[i] = rng.Float32()
+		require.NoError(t, q.Push(uint32(i), prios[i]))
+	}
+	require.Equal(t, 1000, q.Len())
+	assert.Len(t, runFiles(t, dir), 333)
+
+	var last float32
+	for i := 0; q.Len() > 0; i++ {
+		topID, topPrio := q.Top()
+		id, prio, err := q.Pop()
+		require.NoError(t, err)
+		require.Equal(t, topID, id)
+		require.Equal(t, topPrio, prio)
+		require.Equal(t, prios[id], prio)
+		require.GreaterOrEqual(t, prio, last)
+		last = prio
+	}
+
+	assert.Empty(t, runFiles(t, dir), "exhausted runs should be removed")
+}
+
+func TestQueue_Random(t *testing.T) {
+	dir := t.TempDir()
+	q := external.New(external.Options{MaxItems: 16, TempDir: dir})
+	defer q.Close()
+
+	model := prioqueue.NewMinHeap(0)
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < 20_000; i++ {
+		if rng.Intn(3) > 0 {
+			prio := float32(rng.Intn(100))
+			require.NoError(t, q.Push(uint32(i), prio))
+			model.Push(uint32(i), prio)
+			continue
+		}
+
+		_, prio, err := q.Pop()
+		require.NoError(t, err)
+		_, expected := model.Pop()
+		require.Equal(t, expected, prio)
+		require.Equal(t, model.Len(), q.Len())
+	}
+}
+
+func TestQueue_Close(t *testing.T) {
+	dir := t.TempDir()
+	q := external.New(external.Options{MaxItems: 1, TempDir: dir})
+	for i := 0; i < 10; i++ {
+		require.NoError(t, q.Push(uint32(i), float32(i)))
+	}
+	assert.Len(t, runFiles(t, dir), 9)
+
+	require.NoError(t, q.Close())
+	assert.Empty(t, runFiles(t, dir))
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestQueue_SpillError(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "does-not-exist")
+	q := external.New(external.Options{MaxItems: 2, TempDir: dir})
+	defer q.Close()
+
+	require.NoError(t, q.Push(1, 1))
+	require.NoError(t, q.Push(2, 2))
+	assert.Error(t, q.Push(3, 3))
+
+	// The buffered items are not lost if spilling fails.
+	assert.Equal(t, 2, q.Len())
+	id, _, err := q.Pop()
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, id)
+}
+
+// runFiles returns the names of all files in the directory.
+func runFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestQueue_ReadError(t *testing.T) {
+	dir := t.TempDir()
+	const n = 20_000 // more than fits into the read buffer of a run
+	q := external.New(external.Options{MaxItems: n, TempDir: dir})
+	defer q.Close()
+
+	for i := 0; i <= n; i++ {
+		require.NoError(t, q.Push(uint32(i), float32(i)))
+	}
+
+	// Cut the run in the middle of a record after the first buffer.
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.NoError(t, os.Truncate(filepath.Join(dir, files[0].Name()), 100_004))
+
+	for i := 0; i <= n; i++ {
+		id, prio, err := q.Pop()
+		require.EqualValues(t, i, id, "the popped item should not be lost")
+		require.EqualValues(t, i, prio)
+		if err != nil {
+			assert.Greater(t, i, 8000, "the first buffer should be read without errors")
+			return
+		}
+	}
+
+	t.Fatal("reading the cut run should fail")
+}
@@ -0,0 +1,42 @@
+//go:build go1.18
+// +build go1.18
+
+package prioqueue_test
+
+import (
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/fgrosse/prioqueue/prioqueuetest"
+)
+
+// fuzzSeeds are operations which are added to the corpus of the fuzz tests.
+// Each operation consists of three bytes (see prioqueuetest.DecodeOps).
+var fuzzSeeds = [][]byte{
+	{},
+	{0, 1, 10, 0, 2, 20, 0, 3, 30, 1, 0, 0, 1, 0, 0, 1, 0, 0, 1, 0, 0},
+	{0, 1, 10, 0, 2, 10, 0, 3, 10, 2, 4, 10, 1, 0, 0, 1, 0, 0, 3, 0, 0},
+	{2, 1, 1, 0, 5, 200, 0, 6, 100, 2, 7, 150, 1, 0, 0, 3, 0, 0, 1, 0, 0},
+}
+
+func FuzzMaxHeap(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ops := prioqueuetest.DecodeOps(data)
+		prioqueuetest.Check(t, prioqueue.NewMaxHeap(0), prioqueuetest.MaxFirst, ops)
+	})
+}
+
+func FuzzMinHeap(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ops := prioqueuetest.DecodeOps(data)
+		prioqueuetest.Check(t, prioqueue.NewMinHeap(0), prioqueuetest.MinFirst, ops)
+	})
+}
@@ -0,0 +1,56 @@
+package graph
+
+import (
+	"math"
+
+	"github.com/fgrosse/prioqueue"
+)
+
+// AStar computes the shortest path from the source to the target node using
+// the A* search algorithm. The heuristic must return an estimate of the length
+// of the shortest path from a node to the target. If the heuristic never
+// overestimates this length, the returned path is a shortest path. All edge
+// weights must be non-negative.
+//
+// AStar returns the nodes on the path, including source and target, as well as
+// the length of the path. If the target is not reachable, ok is false.
+func AStar(g Graph, source, target uint32, heuristic func(node uint32) float32) (path []uint32, length float32, ok bool) {
+	n := g.Len()
+	dist := make([]float32, n)
+	prev := make([]uint32, n)
+
+	inf := float32(math.Inf(1))
+	for i := range dist {
+		dist[i] = inf
+		prev[i] = NoNode
+	}
+
+	queue := prioqueue.NewIndexedMinHeap(n)
+
+	var u uint32
+	relax := func(v uint32, weight float32) {
+		if d := dist[u] + weight; d < dist[v] {
+			dist[v] = d
+			prev[v] = u
+
+			// This either queues v for the first time, lowers its priority
+			// or queues it again if a shorter path to v was found after it
+			// has been expanded already.
+			queue.Push(v, d+heuristic(v))
+		}
+	}
+
+	dist[source] = 0
+	queue.Push(source, heuristic(source))
+	for queue.Len() > 0 {
+		u, _ = queue.Pop()
+		if u == target {
+			p := &Paths{Source: source, Dist: dist, Prev: prev}
+			return p.To(target), dist[target], true
+		}
+
+		g.Edges(u, relax)
+	}
+
+	return nil, inf, false
+}
@@ -0,0 +1,69 @@
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/fgrosse/prioqueue/graph"
+	"github.com/stretchr/testify/assert"
+)
+
+// gridGraph returns a graph of a width x height grid in which each node is
+// connected to its four neighbors. Moving between two nodes costs at least 1.
+func gridGraph(width, height int, seed int64) *graph.AdjacencyList {
+	rng := rand.New(rand.NewSource(seed))
+	g := graph.NewAdjacencyList(width * height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			n := uint32(y*width + x)
+			if x+1 < width {
+				g.AddUndirectedEdge(n, n+1, 1+float32(rng.Intn(5)))
+			}
+			if y+1 < height {
+				g.AddUndirectedEdge(n, n+uint32(width), 1+float32(rng.Intn(5)))
+			}
+		}
+	}
+	return g
+}
+
+func TestAStar(t *testing.T) {
+	const width, height = 30, 20
+
+	for seed := int64(0); seed < 5; seed++ {
+		g := gridGraph(width, height, seed)
+		target := uint32(width*height - 1)
+		manhattan := func(n uint32) float32 {
+			x, y := int(n)%width, int(n)/width
+			return float32((width - 1 - x) + (height - 1 - y))
+		}
+
+		path, length, ok := graph.AStar(g, 0, target, manhattan)
+		assert.True(t, ok)
+
+		p := graph.ShortestPaths(g, 0)
+		assert.Equal(t, p.Dist[target], length)
+		assert.Equal(t, uint32(0), path[0])
+		assert.Equal(t, target, path[len(path)-1])
+	}
+}
+
+func TestAStar_ZeroHeuristic(t *testing.T) {
+	g := randomGraph(100, 500, 42)
+	dist := bellmanFord(g, 0)
+	zero := func(uint32) float32 { return 0 }
+
+	for target := range dist {
+		path, length, ok := graph.AStar(g, 0, uint32(target), zero)
+		if math.IsInf(float64(dist[target]), 1) {
+			assert.False(t, ok)
+			assert.Nil(t, path)
+			continue
+		}
+
+		assert.True(t, ok)
+		assert.Equal(t, dist[target], length)
+		assert.Equal(t, uint32(target), path[len(path)-1])
+	}
+}
@@ -0,0 +1,297 @@
+package graph_test
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/fgrosse/prioqueue/graph"
+)
+
+// StdHeap is the heap implementation using the container/heap package to
+// provide a baseline for benchmarks
+type StdHeap []*prioqueue.Item
+
+func (h *StdHeap) Len() int {
+	return len(*h)
+}
+
+func (h *StdHeap) Less(i, j int) bool {
+	return (*h)[i].Prio < (*h)[j].Prio
+}
+
+func (h *StdHeap) Swap(i, j int) {
+	(*h)[i], (*h)[j] = (*h)[j], (*h)[i]
+}
+
+func (h *StdHeap) Push(x interface{}) {
+	*h = append(*h, x.(*prioqueue.Item))
+}
+
+func (h *StdHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[0 : n-1]
+	return x
+}
+
+// stdlibShortestPaths implements Dijkstra's algorithm using the container/heap
+// package. Since that heap does not support decreasing the key of an item, a
+// node is pushed again whenever a shorter path to it is found and outdated
+// entries are skipped when they are popped.
+func stdlibShortestPaths(g graph.Graph, source uint32) []float32 {
+	dist := make([]float32, g.Len())
+	for i := range dist {
+		dist[i] = float32(math.Inf(1))
+	}
+
+	done := make([]bool, g.Len())
+	h := new(StdHeap)
+
+	var u uint32
+	relax := func(v uint32, weight float32) {
+		if d := dist[u] + weight; d < dist[v] {
+			dist[v] = d
+			heap.Push(h, &prioqueue.Item{ID: v, Prio: d})
+		}
+	}
+
+	dist[source] = 0
+	heap.Push(h, &prioqueue.Item{ID: source, Prio: 0})
+	for h.Len() > 0 {
+		u = heap.Pop(h).(*prioqueue.Item).ID
+		if done[u] {
+			continue
+		}
+
+		done[u] = true
+		g.Edges(u, relax)
+	}
+
+	return dist
+}
+
+// stdlibAStar implements the A* search algorithm using the container/heap
+// package in the same way as stdlibShortestPaths. It only returns the length
+// of the shortest path from source to target.
+func stdlibAStar(g graph.Graph, source, target uint32, heuristic func(node uint32) float32) (length float32, ok bool) {
+	dist := make([]float32, g.Len())
+	for i := range dist {
+		dist[i] = float32(math.Inf(1))
+	}
+
+	h := new(StdHeap)
+
+	var u uint32
+	relax := func(v uint32, weight float32) {
+		if d := dist[u] + weight; d < dist[v] {
+			dist[v] = d
+			heap.Push(h, &prioqueue.Item{ID: v, Prio: d + heuristic(v)})
+		}
+	}
+
+	dist[source] = 0
+	heap.Push(h, &prioqueue.Item{ID: source, Prio: heuristic(source)})
+	for h.Len() > 0 {
+		item := heap.Pop(h).(*prioqueue.Item)
+		u = item.ID
+		if item.Prio > dist[u]+heuristic(u) {
+			continue // a shorter path to u was found after this item was pushed
+		}
+		if u == target {
+			return dist[target], true
+		}
+
+		g.Edges(u, relax)
+	}
+
+	return dist[target], false
+}
+
+// stdlibMinimumSpanningTree implements Prim's algorithm using the
+// container/heap package in the same way as stdlibShortestPaths. It only
+// returns the total weight of the minimum spanning forest.
+func stdlibMinimumSpanningTree(g graph.Graph) (weight float32) {
+	cost := make([]float32, g.Len())
+	for i := range cost {
+		cost[i] = float32(math.Inf(1))
+	}
+
+	inTree := make([]bool, g.Len())
+	h := new(StdHeap)
+
+	relax := func(v uint32, w float32) {
+		if !inTree[v] && w < cost[v] {
+			cost[v] = w
+			heap.Push(h, &prioqueue.Item{ID: v, Prio: w})
+		}
+	}
+
+	for root := 0; root < g.Len(); root++ {
+		if inTree[root] {
+			continue
+		}
+
+		cost[root] = 0
+		heap.Push(h, &prioqueue.Item{ID: uint32(root), Prio: 0})
+		for h.Len() > 0 {
+			u := heap.Pop(h).(*prioqueue.Item).ID
+			if inTree[u] {
+				continue
+			}
+
+			inTree[u] = true
+			weight += cost[u]
+			g.Edges(u, relax)
+		}
+	}
+
+	return weight
+}
+
+// benchmarkGrid returns the grid which is used by the A* benchmarks together
+// with its target node and the Manhattan distance to the target.
+func benchmarkGrid() (g *graph.AdjacencyList, target uint32, heuristic func(node uint32) float32) {
+	const width, height = 300, 300
+	g = gridGraph(width, height, 42)
+	target = uint32(width*height - 1)
+	heuristic = func(n uint32) float32 {
+		x, y := int(n)%width, int(n)/width
+		return float32((width - 1 - x) + (height - 1 - y))
+	}
+	return g, target, heuristic
+}
+
+// spanningTreeGraph returns a random graph with 10.000 nodes and 100.000
+// undirected edges, which is used by the minimum spanning tree benchmarks.
+func spanningTreeGraph() *graph.AdjacencyList {
+	rng := rand.New(rand.NewSource(42))
+	g := graph.NewAdjacencyList(10_000)
+	for i := 0; i < 100_000; i++ {
+		a := uint32(rng.Intn(g.Len()))
+		c := uint32(rng.Intn(g.Len()))
+		g.AddUndirectedEdge(a, c, float32(rng.Intn(100)))
+	}
+	return g
+}
+
+func TestStdlibShortestPaths(t *testing.T) {
+	g := randomGraph(100, 500, 42)
+	p := graph.ShortestPaths(g, 0)
+	dist := stdlibShortestPaths(g, 0)
+	for i := range dist {
+		if dist[i] != p.Dist[i] {
+			t.Errorf("Incorrect distance of node %d: %v != %v", i, dist[i], p.Dist[i])
+		}
+	}
+}
+
+// BenchmarkShortestPaths tests how fast ShortestPaths is on a random graph with
+// 10.000 nodes and 100.000 edges.
+func BenchmarkShortestPaths(b *testing.B) {
+	g := randomGraph(10_000, 100_000, 42)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		graph.ShortestPaths(g, 0)
+	}
+}
+
+// BenchmarkStdlib_ShortestPaths tests how fast Dijkstra's algorithm is on the
+// same graph as BenchmarkShortestPaths if it is implemented using the
+// container/heap package.
+func BenchmarkStdlib_ShortestPaths(b *testing.B) {
+	g := randomGraph(10_000, 100_000, 42)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		stdlibShortestPaths(g, 0)
+	}
+}
+
+func TestStdlibAStar(t *testing.T) {
+	const width, height = 30, 20
+	g := gridGraph(width, height, 42)
+	target := uint32(width*height - 1)
+	manhattan := func(n uint32) float32 {
+		x, y := int(n)%width, int(n)/width
+		return float32((width - 1 - x) + (height - 1 - y))
+	}
+
+	_, expected, ok := graph.AStar(g, 0, target, manhattan)
+	if !ok {
+		t.Fatal("AStar did not find a path")
+	}
+	if length, ok := stdlibAStar(g, 0, target, manhattan); !ok || length != expected {
+		t.Errorf("Incorrect length of the path: %v != %v", length, expected)
+	}
+}
+
+func TestStdlibMinimumSpanningTree(t *testing.T) {
+	g := graph.NewAdjacencyList(100)
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < 300; i++ {
+		a := uint32(rng.Intn(g.Len()))
+		c := uint32(rng.Intn(g.Len()))
+		g.AddUndirectedEdge(a, c, float32(rng.Intn(100)))
+	}
+
+	_, expected := graph.MinimumSpanningTree(g)
+	if weight := stdlibMinimumSpanningTree(g); weight != expected {
+		t.Errorf("Incorrect weight of the spanning tree: %v != %v", weight, expected)
+	}
+}
+
+// BenchmarkAStar tests how fast AStar finds a path through a 300x300 grid.
+func BenchmarkAStar(b *testing.B) {
+	g, target, manhattan := benchmarkGrid()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		graph.AStar(g, 0, target, manhattan)
+	}
+}
+
+// BenchmarkStdlib_AStar tests how fast the A* search algorithm is on the same
+// grid as BenchmarkAStar if it is implemented using the container/heap
+// package.
+func BenchmarkStdlib_AStar(b *testing.B) {
+	g, target, manhattan := benchmarkGrid()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		stdlibAStar(g, 0, target, manhattan)
+	}
+}
+
+// BenchmarkMinimumSpanningTree tests how fast MinimumSpanningTree is on a random
+// graph with 10.000 nodes and 100.000 undirected edges.
+func BenchmarkMinimumSpanningTree(b *testing.B) {
+	g := spanningTreeGraph()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		graph.MinimumSpanningTree(g)
+	}
+}
+
+// BenchmarkStdlib_MinimumSpanningTree tests how fast Prim's algorithm is on
+// the same graph as BenchmarkMinimumSpanningTree if it is implemented using the
+// container/heap package.
+func BenchmarkStdlib_MinimumSpanningTree(b *testing.B) {
+	g := spanningTreeGraph()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		stdlibMinimumSpanningTree(g)
+	}
+}
@@ -0,0 +1,72 @@
+// Package graph implements shortest path and minimum spanning tree algorithms
+// on top of the priority queues of the prioqueue package.
+package graph
+
+import "math"
+
+// NoNode is used in place of a node ID if there is no such node, e.g. as the
+// predecessor of a node which is not reachable.
+const NoNode = math.MaxUint32
+
+// Graph is a directed graph with weighted edges. The nodes of a Graph are
+// identified by the integers 0 to Len()-1.
+//
+// Undirected graphs are represented by adding an edge in both directions.
+type Graph interface {
+	// Len returns the amount of nodes in the graph.
+	Len() int
+
+	// Edges calls fn for each edge which starts at the given node.
+	Edges(node uint32, fn func(to uint32, weight float32))
+}
+
+// Edge is a weighted edge between two nodes.
+type Edge struct {
+	From   uint32
+	To     uint32
+	Weight float32
+}
+
+// AdjacencyList is a Graph which stores the outgoing edges of each node in a
+// list.
+type AdjacencyList struct {
+	edges [][]Edge
+}
+
+// NewAdjacencyList returns a new AdjacencyList with n nodes and no edges.
+func NewAdjacencyList(n int) *AdjacencyList {
+	return &AdjacencyList{edges: make([][]Edge, n)}
+}
+
+// AddEdge adds a directed edge from one node to another. The graph grows
+// automatically if one of the nodes does not exist yet.
+func (g *AdjacencyList) AddEdge(from, to uint32, weight float32) {
+	g.grow(from)
+	g.grow(to)
+	g.edges[from] = append(g.edges[from], Edge{From: from, To: to, Weight: weight})
+}
+
+// AddUndirectedEdge adds an edge in both directions between two nodes.
+func (g *AdjacencyList) AddUndirectedEdge(a, b uint32, weight float32) {
+	g.AddEdge(a, b, weight)
+	g.AddEdge(b, a, weight)
+}
+
+// Len returns the amount of nodes in the graph.
+func (g *AdjacencyList) Len() int {
+	return len(g.edges)
+}
+
+// Edges calls fn for each edge which starts at the given node.
+func (g *AdjacencyList) Edges(node uint32, fn func(to uint32, weight float32)) {
+	for _, e := range g.edges[node] {
+		fn(e.To, e.Weight)
+	}
+}
+
+// grow adds nodes to the graph until it contains the given node.
+func (g *AdjacencyList) grow(node uint32) {
+	for int(node) >= len(g.edges) {
+		g.edges = append(g.edges, nil)
+	}
+}
@@ -0,0 +1,43 @@
+package graph_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/fgrosse/prioqueue/graph"
+	"github.com/stretchr/testify/assert"
+)
+
+// randomGraph returns a graph with n nodes and m random directed edges with
+// integer weights.
+func randomGraph(n, m int, seed int64) *graph.AdjacencyList {
+	rng := rand.New(rand.NewSource(seed))
+	g := graph.NewAdjacencyList(n)
+	for i := 0; i < m; i++ {
+		from := uint32(rng.Intn(n))
+		to := uint32(rng.Intn(n))
+		g.AddEdge(from, to, float32(rng.Intn(100)))
+	}
+	return g
+}
+
+func TestAdjacencyList(t *testing.T) {
+	g := graph.NewAdjacencyList(2)
+	g.AddEdge(0, 1, 1.5)
+	g.AddUndirectedEdge(1, 3, 2)
+	assert.Equal(t, 4, g.Len(), "graph should grow automatically")
+
+	var edges []graph.Edge
+	for n := 0; n < g.Len(); n++ {
+		from := uint32(n)
+		g.Edges(from, func(to uint32, weight float32) {
+			edges = append(edges, graph.Edge{From: from, To: to, Weight: weight})
+		})
+	}
+
+	assert.Equal(t, []graph.Edge{
+		{From: 0, To: 1, Weight: 1.5},
+		{From: 1, To: 3, Weight: 2},
+		{From: 3, To: 1, Weight: 2},
+	}, edges)
+}
@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"math"
+
+	"github.com/fgrosse/prioqueue"
+)
+
+// Paths contains the shortest paths from a source node to all other nodes of a
+// graph.
+type Paths struct {
+	// Source is the node at which all paths start.
+	Source uint32
+
+	// Dist contains the length of the shortest path from the source to each
+	// node. It is positive infinity for nodes that are not reachable.
+	Dist []float32
+
+	// Prev contains the predecessor of each node on its shortest path. It is
+	// NoNode for the source and all nodes that are not reachable.
+	Prev []uint32
+}
+
+// To returns the nodes on the shortest path from the source to the target,
+// including both of them. It returns nil if the target is not reachable.
+func (p *Paths) To(target uint32) []uint32 {
+	if int(target) >= len(p.Dist) || math.IsInf(float64(p.Dist[target]), 1) {
+		return nil
+	}
+
+	var path []uint32
+	for n := target; n != NoNode; n = p.Prev[n] {
+		path = append(path, n)
+	}
+
+	// reverse the path so it starts at the source
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
+
+// ShortestPaths computes the shortest paths from the source to all other nodes
+// of the graph using Dijkstra's algorithm. All edge weights must be
+// non-negative.
+//
+// Time Complexity
+//
+//	ShortestPaths takes O((V + E) log V) for a graph with V nodes and E edges.
+func ShortestPaths(g Graph, source uint32) *Paths {
+	n := g.Len()
+	p := &Paths{
+		Source: source,
+		Dist:   make([]float32, n),
+		Prev:   make([]uint32, n),
+	}
+
+	inf := float32(math.Inf(1))
+	for i := range p.Dist {
+		p.Dist[i] = inf
+		p.Prev[i] = NoNode
+	}
+
+	done := make([]bool, n)
+	queue := prioqueue.NewIndexedMinHeap(n)
+
+	var u uint32
+	relax := func(v uint32, weight float32) {
+		if done[v] {
+			return
+		}
+
+		if d := p.Dist[u] + weight; d < p.Dist[v] {
+			p.Dist[v] = d
+			p.Prev[v] = u
+			queue.Push(v, d) // decreases the key if v is already queued
+		}
+	}
+
+	p.Dist[source] = 0
+	queue.Push(source, 0)
+	for queue.Len() > 0 {
+		u, _ = queue.Pop()
+		done[u] = true
+		g.Edges(u, relax)
+	}
+
+	return p
+}
@@ -0,0 +1,78 @@
+package graph_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/fgrosse/prioqueue/graph"
+	"github.com/stretchr/testify/assert"
+)
+
+// bellmanFord computes the distances from the source to all nodes to provide
+// the ground truth for the tests.
+func bellmanFord(g graph.Graph, source uint32) []float32 {
+	dist := make([]float32, g.Len())
+	for i := range dist {
+		dist[i] = float32(math.Inf(1))
+	}
+	dist[source] = 0
+
+	for i := 0; i < g.Len(); i++ {
+		for n := 0; n < g.Len(); n++ {
+			from := uint32(n)
+			g.Edges(from, func(to uint32, weight float32) {
+				if d := dist[from] + weight; d < dist[to] {
+					dist[to] = d
+				}
+			})
+		}
+	}
+
+	return dist
+}
+
+func TestShortestPaths(t *testing.T) {
+	g := graph.NewAdjacencyList(6)
+	g.AddEdge(0, 1, 7)
+	g.AddEdge(0, 2, 9)
+	g.AddEdge(0, 5, 14)
+	g.AddEdge(1, 2, 10)
+	g.AddEdge(1, 3, 15)
+	g.AddEdge(2, 3, 11)
+	g.AddEdge(2, 5, 2)
+	g.AddEdge(3, 4, 6)
+	g.AddEdge(5, 4, 9)
+
+	p := graph.ShortestPaths(g, 0)
+	assert.Equal(t, []float32{0, 7, 9, 20, 20, 11}, p.Dist)
+	assert.Equal(t, []uint32{0, 2, 5, 4}, p.To(4))
+	assert.Equal(t, []uint32{0}, p.To(0))
+	assert.Nil(t, p.To(42))
+
+	p = graph.ShortestPaths(g, 4)
+	assert.True(t, math.IsInf(float64(p.Dist[0]), 1))
+	assert.Equal(t, uint32(graph.NoNode), p.Prev[0])
+	assert.Nil(t, p.To(0))
+}
+
+func TestShortestPaths_Random(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		g := randomGraph(100, 500, seed)
+		p := graph.ShortestPaths(g, 0)
+		assert.Equal(t, bellmanFord(g, 0), p.Dist)
+
+		// Check that the paths are consistent with the distances.
+		for n := range p.Dist {
+			path := p.To(uint32(n))
+			if path == nil {
+				continue
+			}
+
+			assert.Equal(t, uint32(0), path[0])
+			assert.Equal(t, uint32(n), path[len(path)-1])
+			if len(path) > 1 {
+				assert.Equal(t, path[len(path)-2], p.Prev[n])
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"math"
+
+	"github.com/fgrosse/prioqueue"
+)
+
+// MinimumSpanningTree computes a minimum spanning tree of an undirected graph
+// using Prim's algorithm and returns its edges as well as their total weight.
+// Each undirected edge must be contained in the graph in both directions.
+//
+// If the graph is not connected, a minimum spanning forest is returned which
+// contains a minimum spanning tree for each connected component.
+//
+// Time Complexity
+//
+//	MinimumSpanningTree takes O((V + E) log V) for a graph with V nodes and E
+//	edges.
+func MinimumSpanningTree(g Graph) (edges []Edge, weight float32) {
+	n := g.Len()
+	cost := make([]float32, n)  // weight of the cheapest edge into the tree
+	parent := make([]uint32, n) // other end of that edge
+	inTree := make([]bool, n)
+
+	inf := float32(math.Inf(1))
+	for i := range cost {
+		cost[i] = inf
+		parent[i] = NoNode
+	}
+
+	queue := prioqueue.NewIndexedMinHeap(n)
+
+	var u uint32
+	relax := func(v uint32, w float32) {
+		if !inTree[v] && w < cost[v] {
+			cost[v] = w
+			parent[v] = u
+			queue.Push(v, w)
+		}
+	}
+
+	for root := 0; root < n; root++ {
+		if inTree[root] {
+			continue
+		}
+
+		// start a new tree for the next connected component
+		cost[root] = 0
+		queue.Push(uint32(root), 0)
+		for queue.Len() > 0 {
+			u, _ = queue.Pop()
+			inTree[u] = true
+			if parent[u] != NoNode {
+				edges = append(edges, Edge{From: parent[u], To: u, Weight: cost[u]})
+				weight += cost[u]
+			}
+
+			g.Edges(u, relax)
+		}
+	}
+
+	return edges, weight
+}
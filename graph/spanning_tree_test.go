@@ -0,0 +1,84 @@
+package graph_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/fgrosse/prioqueue/graph"
+	"github.com/stretchr/testify/assert"
+)
+
+// kruskal computes the weight of a minimum spanning forest to provide the
+// ground truth for the tests.
+func kruskal(n int, edges []graph.Edge) float32 {
+	sort.Slice(edges, func(i, j int) bool { return edges[i].Weight < edges[j].Weight })
+
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	var weight float32
+	for _, e := range edges {
+		a, b := find(int(e.From)), find(int(e.To))
+		if a != b {
+			parent[a] = b
+			weight += e.Weight
+		}
+	}
+
+	return weight
+}
+
+func TestMinimumSpanningTree(t *testing.T) {
+	g := graph.NewAdjacencyList(5)
+	g.AddUndirectedEdge(0, 1, 2)
+	g.AddUndirectedEdge(0, 3, 6)
+	g.AddUndirectedEdge(1, 2, 3)
+	g.AddUndirectedEdge(1, 3, 8)
+	g.AddUndirectedEdge(1, 4, 5)
+	g.AddUndirectedEdge(2, 4, 7)
+	g.AddUndirectedEdge(3, 4, 9)
+
+	edges, weight := graph.MinimumSpanningTree(g)
+	assert.EqualValues(t, 16, weight)
+	assert.Equal(t, []graph.Edge{
+		{From: 0, To: 1, Weight: 2},
+		{From: 1, To: 2, Weight: 3},
+		{From: 1, To: 4, Weight: 5},
+		{From: 0, To: 3, Weight: 6},
+	}, edges)
+}
+
+func TestMinimumSpanningTree_Random(t *testing.T) {
+	const n = 200
+
+	for seed := int64(0); seed < 10; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		g := graph.NewAdjacencyList(n)
+
+		var all []graph.Edge
+		for i := 0; i < 300; i++ { // sparse enough to have several components
+			e := graph.Edge{
+				From:   uint32(rng.Intn(n)),
+				To:     uint32(rng.Intn(n)),
+				Weight: float32(rng.Intn(100)),
+			}
+			g.AddUndirectedEdge(e.From, e.To, e.Weight)
+			all = append(all, e)
+		}
+
+		edges, weight := graph.MinimumSpanningTree(g)
+		assert.Equal(t, kruskal(n, all), weight)
+		assert.Equal(t, kruskal(n, edges), weight, "result should be a forest")
+	}
+}
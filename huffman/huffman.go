@@ -0,0 +1,245 @@
+// Package huffman builds canonical Huffman codes using a prioqueue.MinHeap.
+package huffman
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/fgrosse/prioqueue"
+)
+
+// maxCodeLen is the maximum length of a code in bits.
+const maxCodeLen = 64
+
+var (
+	// ErrNoSymbols is returned by Build if no symbol has a frequency above zero.
+	ErrNoSymbols = errors.New("huffman: no symbol has a frequency above zero")
+
+	// ErrCodeTooLong is returned by Build if a code would be longer than 64 bits.
+	ErrCodeTooLong = errors.New("huffman: code is longer than 64 bits")
+
+	// ErrUnknownSymbol is returned by Encode if a symbol has no code.
+	ErrUnknownSymbol = errors.New("huffman: symbol has no code")
+
+	// ErrInvalidData is returned by Decode if the data does not contain the
+	// requested amount of symbols or contains a bit sequence which is no code.
+	ErrInvalidData = errors.New("huffman: invalid data")
+)
+
+// Code is the Huffman code of a single symbol.
+type Code struct {
+	// Bits contains the code in its Len lowest bits. The first bit of the code
+	// is the most significant of these bits.
+	Bits uint64
+
+	// Len is the length of the code in bits. Symbols which do not occur have
+	// a length of 0.
+	Len uint8
+}
+
+// Table is a canonical Huffman code for a set of symbols. Symbols are
+// identified by the integers 0 to n-1.
+type Table struct {
+	// Codes contains the code of each symbol.
+	Codes []Code
+
+	counts  [maxCodeLen + 1]int // amount of codes of each length
+	symbols []uint32            // symbols ordered by the length of their code
+}
+
+// Build computes a canonical Huffman code for the symbols 0 to len(freqs)-1
+// where freqs contains the frequency of each symbol. Symbols with a frequency
+// of zero do not get a code.
+//
+// The code is built by repeatedly popping the two nodes with the lowest weight
+// from a MinHeap and pushing a new node with the sum of their weights until
+// only the root of the tree is left. Nodes with equal weight are ordered by
+// their ID, where leaves have lower IDs than inner nodes. Therefore the
+// resulting code is deterministic.
+//
+// Note that the weights are stored as float32, so frequencies above 2^24 are
+// rounded. This may make the code slightly less than optimal but it is always
+// a valid prefix code.
+func Build(freqs []uint32) (*Table, error) {
+	n := len(freqs)
+	h := prioqueue.NewMinHeap(n)
+	h.SetTieBreakByID(true)
+	for symbol, freq := range freqs {
+		if freq > 0 {
+			h.PushItem(&prioqueue.Item{ID: uint32(symbol), Prio: float32(freq)})
+		}
+	}
+
+	if h.Len() == 0 {
+		return nil, ErrNoSymbols
+	}
+
+	lengths := make([]int, n)
+	if h.Len() == 1 {
+		// A single symbol still needs a code of one bit.
+		lengths[h.TopItem().ID] = 1
+		return newTable(lengths), nil
+	}
+
+	// Leaves have the IDs of their symbols and inner nodes get the IDs from n
+	// onwards. Each inner node is created after its children, so its ID is
+	// always larger than theirs.
+	parents := make([]uint32, n, 2*n)
+	next := uint32(n)
+	for h.Len() > 1 {
+		a := h.PopItem()
+		b := h.PopItem()
+
+		parents[a.ID] = next
+		parents[b.ID] = next
+		parents = append(parents, 0)
+
+		a.ID = next
+		a.Prio += b.Prio
+		h.PushItem(a)
+		next++
+	}
+
+	// Compute the depth of all nodes, starting at the root.
+	depths := make([]int, len(parents))
+	for i := len(parents) - 2; i >= 0; i-- {
+		depths[i] = depths[parents[i]] + 1
+	}
+
+	for symbol, freq := range freqs {
+		if freq == 0 {
+			continue
+		}
+		if depths[symbol] > maxCodeLen {
+			return nil, ErrCodeTooLong
+		}
+		lengths[symbol] = depths[symbol]
+	}
+
+	return newTable(lengths), nil
+}
+
+// newTable assigns canonical codes to the symbols, given the length of each
+// code. Symbols are ordered by the length of their code and then by their
+// value and consecutive symbols get consecutive codes.
+func newTable(lengths []int) *Table {
+	t := &Table{Codes: make([]Code, len(lengths))}
+	for symbol, l := range lengths {
+		if l > 0 {
+			t.symbols = append(t.symbols, uint32(symbol))
+			t.counts[l]++
+		}
+	}
+
+	sort.SliceStable(t.symbols, func(i, j int) bool {
+		return lengths[t.symbols[i]] < lengths[t.symbols[j]]
+	})
+
+	var code uint64
+	var prevLen int
+	for _, symbol := range t.symbols {
+		l := lengths[symbol]
+		code <<= uint(l - prevLen)
+		t.Codes[symbol] = Code{Bits: code, Len: uint8(l)}
+		code++
+		prevLen = l
+	}
+
+	return t
+}
+
+// Encode encodes the symbols and returns the resulting bits. The last byte is
+// padded with zero bits.
+func (t *Table) Encode(symbols []uint32) ([]byte, error) {
+	var w bitWriter
+	for _, symbol := range symbols {
+		if int(symbol) >= len(t.Codes) || t.Codes[symbol].Len == 0 {
+			return nil, ErrUnknownSymbol
+		}
+
+		c := t.Codes[symbol]
+		for i := int(c.Len) - 1; i >= 0; i-- {
+			w.writeBit(byte(c.Bits>>uint(i)) & 1)
+		}
+	}
+
+	return w.data, nil
+}
+
+// Decode decodes n symbols from the data.
+func (t *Table) Decode(data []byte, n int) ([]uint32, error) {
+	symbols := make([]uint32, 0, n)
+	r := bitReader{data: data}
+	for len(symbols) < n {
+		symbol, err := t.decodeSymbol(&r)
+		if err != nil {
+			return nil, err
+		}
+		symbols = append(symbols, symbol)
+	}
+
+	return symbols, nil
+}
+
+// decodeSymbol reads the bits of a single code and returns its symbol. Since
+// the codes are canonical, all codes of the same length are consecutive
+// integers, so it is enough to know the first code and the first symbol of
+// each length.
+func (t *Table) decodeSymbol(r *bitReader) (uint32, error) {
+	var (
+		code  uint64 // bits read so far
+		first uint64 // first code of the current length
+		index int    // index of the first symbol of the current length
+	)
+
+	for l := 1; l <= maxCodeLen; l++ {
+		bit, ok := r.readBit()
+		if !ok {
+			return 0, ErrInvalidData
+		}
+
+		code = code<<1 | uint64(bit)
+		count := uint64(t.counts[l])
+		if code-first < count {
+			return t.symbols[index+int(code-first)], nil
+		}
+
+		index += int(count)
+		first = (first + count) << 1
+	}
+
+	return 0, ErrInvalidData
+}
+
+// bitWriter appends bits to a byte slice, starting with the most significant
+// bit of each byte.
+type bitWriter struct {
+	data []byte
+	n    uint // amount of bits written
+}
+
+func (w *bitWriter) writeBit(bit byte) {
+	if w.n%8 == 0 {
+		w.data = append(w.data, 0)
+	}
+
+	w.data[len(w.data)-1] |= bit << (7 - w.n%8)
+	w.n++
+}
+
+// bitReader reads bits from a byte slice in the order of the bitWriter.
+type bitReader struct {
+	data []byte
+	n    uint // amount of bits read
+}
+
+func (r *bitReader) readBit() (byte, bool) {
+	i := r.n / 8
+	if i >= uint(len(r.data)) {
+		return 0, false
+	}
+
+	bit := r.data[i] >> (7 - r.n%8) & 1
+	r.n++
+	return bit, true
+}
@@ -0,0 +1,195 @@
+package huffman_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/fgrosse/prioqueue/huffman"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// optimalCost returns the total amount of bits of an optimal prefix code for
+// the given frequencies, which is the sum of the weights of all inner nodes of
+// the Huffman tree.
+func optimalCost(freqs []uint32) uint64 {
+	var weights []uint64
+	for _, f := range freqs {
+		if f > 0 {
+			weights = append(weights, uint64(f))
+		}
+	}
+
+	var cost uint64
+	for len(weights) > 1 {
+		sort.Slice(weights, func(i, j int) bool { return weights[i] < weights[j] })
+		sum := weights[0] + weights[1]
+		cost += sum
+		weights = append(weights[2:], sum)
+	}
+
+	return cost
+}
+
+func TestBuild(t *testing.T) {
+	freqs := []uint32{45, 13, 12, 16, 9, 5}
+	table, err := huffman.Build(freqs)
+	require.NoError(t, err)
+
+	assert.Equal(t, []huffman.Code{
+		{Bits: 0b0, Len: 1},
+		{Bits: 0b100, Len: 3},
+		{Bits: 0b101, Len: 3},
+		{Bits: 0b110, Len: 3},
+		{Bits: 0b1110, Len: 4},
+		{Bits: 0b1111, Len: 4},
+	}, table.Codes)
+}
+
+func TestBuild_Random(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < 100; i++ {
+		freqs := make([]uint32, 1+rng.Intn(300))
+		for j := range freqs {
+			if rng.Intn(4) > 0 {
+				freqs[j] = uint32(rng.Intn(1000))
+			}
+		}
+		freqs[rng.Intn(len(freqs))] = 1 // at least one symbol must occur
+
+		table, err := huffman.Build(freqs)
+		require.NoError(t, err)
+
+		// The code must be optimal and satisfy the Kraft equality.
+		var cost uint64
+		var kraft float64
+		var symbols int
+		for symbol, c := range table.Codes {
+			if freqs[symbol] == 0 {
+				assert.Zero(t, c.Len)
+				continue
+			}
+
+			cost += uint64(c.Len) * uint64(freqs[symbol])
+			kraft += 1 / float64(uint64(1)<<c.Len)
+			symbols++
+		}
+
+		if symbols > 1 {
+			assert.Equal(t, optimalCost(freqs), cost)
+			assert.Equal(t, 1.0, kraft)
+		}
+
+		// Building the code again must yield exactly the same code.
+		again, err := huffman.Build(freqs)
+		require.NoError(t, err)
+		assert.Equal(t, table.Codes, again.Codes)
+	}
+}
+
+func TestBuild_Ties(t *testing.T) {
+	freqs := make([]uint32, 16)
+	for i := range freqs {
+		freqs[i] = 7
+	}
+
+	table, err := huffman.Build(freqs)
+	require.NoError(t, err)
+	for symbol, c := range table.Codes {
+		assert.Equal(t, huffman.Code{Bits: uint64(symbol), Len: 4}, c)
+	}
+}
+
+func TestBuild_SingleSymbol(t *testing.T) {
+	table, err := huffman.Build([]uint32{0, 0, 5})
+	require.NoError(t, err)
+	assert.Equal(t, []huffman.Code{{}, {}, {Bits: 0, Len: 1}}, table.Codes)
+
+	data, err := table.Encode([]uint32{2, 2, 2})
+	require.NoError(t, err)
+	symbols, err := table.Decode(data, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{2, 2, 2}, symbols)
+}
+
+func TestBuild_NoSymbols(t *testing.T) {
+	_, err := huffman.Build(nil)
+	assert.Equal(t, huffman.ErrNoSymbols, err)
+
+	_, err = huffman.Build([]uint32{0, 0})
+	assert.Equal(t, huffman.ErrNoSymbols, err)
+}
+
+func TestTable_RoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < 100; i++ {
+		// generate a message with a skewed distribution of symbols
+		msg := make([]uint32, rng.Intn(1000))
+		for j := range msg {
+			msg[j] = uint32(rng.ExpFloat64() * 10)
+		}
+
+		var freqs []uint32
+		for _, symbol := range msg {
+			for int(symbol) >= len(freqs) {
+				freqs = append(freqs, 0)
+			}
+			freqs[symbol]++
+		}
+		if len(msg) == 0 {
+			freqs = []uint32{1}
+		}
+
+		table, err := huffman.Build(freqs)
+		require.NoError(t, err)
+
+		data, err := table.Encode(msg)
+		require.NoError(t, err)
+
+		decoded, err := table.Decode(data, len(msg))
+		require.NoError(t, err)
+		assert.Equal(t, msg, decoded)
+	}
+}
+
+func TestTable_Errors(t *testing.T) {
+	table, err := huffman.Build([]uint32{1, 0, 3})
+	require.NoError(t, err)
+
+	_, err = table.Encode([]uint32{1})
+	assert.Equal(t, huffman.ErrUnknownSymbol, err)
+	_, err = table.Encode([]uint32{3})
+	assert.Equal(t, huffman.ErrUnknownSymbol, err)
+
+	data, err := table.Encode([]uint32{0, 2, 2})
+	require.NoError(t, err)
+	_, err = table.Decode(data, 100)
+	assert.Equal(t, huffman.ErrInvalidData, err)
+}
+
+func ExampleBuild() {
+	msg := "abracadabra"
+	freqs := make([]uint32, 256)
+	for i := range msg {
+		freqs[msg[i]]++
+	}
+
+	table, err := huffman.Build(freqs)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, symbol := range "abcdr" {
+		c := table.Codes[symbol]
+		fmt.Printf("%c: %0*b\n", symbol, c.Len, c.Bits)
+	}
+
+	// Output:
+	// a: 0
+	// b: 100
+	// c: 101
+	// d: 110
+	// r: 111
+}
@@ -0,0 +1,195 @@
+package prioqueue
+
+// IndexedMinHeap is a MinHeap which additionally keeps track of the position of
+// each item in the heap. This makes it possible to change the priority of an
+// item that is already in the queue (i.e. "decrease-key") or to remove an
+// arbitrary item, both in O(log n) time. This is needed by many graph
+// algorithms such as Dijkstra's shortest paths or Prim's minimum spanning tree.
+//
+// The index is a slice which is addressed by the ID of the items. Therefore,
+// IDs should be small integers (e.g. the node IDs of a graph) since the memory
+// used by the index grows with the largest ID that was pushed into the queue.
+// Each ID can be in the queue at most once.
+//
+// In contrast to the MinHeap, the IndexedMinHeap stores its items by value and
+// does not allocate memory for each item.
+//
+// Time Complexity
+//
+//   Push, Pop and Remove take O(log n). Top and Contains happen in constant time.
+type IndexedMinHeap struct {
+	items []Item
+	index []int // index[id]-1 is the position of id in items, 0 means absent
+}
+
+// NewIndexedMinHeap returns a new IndexedMinHeap instance which contains a
+// pre-allocated backing array for the stored items and an index for all IDs
+// less than size. Usage of this function or setting a correct size is
+// optional. The backing array and the index grow automatically.
+func NewIndexedMinHeap(size int) *IndexedMinHeap {
+	h := new(IndexedMinHeap)
+	if size > 0 {
+		h.items = make([]Item, 0, size)
+		h.index = make([]int, size)
+	}
+	return h
+}
+
+// Top returns the ID and priority of the item with the lowest priority value in
+// the queue without removing it.
+func (h *IndexedMinHeap) Top() (id uint32, prio float32) {
+	if len(h.items) == 0 {
+		return 0, 0
+	}
+
+	return h.items[0].ID, h.items[0].Prio
+}
+
+// Len returns the amount of elements in the queue.
+func (h *IndexedMinHeap) Len() int {
+	return len(h.items)
+}
+
+// Contains returns true if an item with the given ID is in the queue.
+func (h *IndexedMinHeap) Contains(id uint32) bool {
+	return int(id) < len(h.index) && h.index[id] > 0
+}
+
+// Reset empties the queue. Note that the memory of the backing array and the
+// index is not released.
+func (h *IndexedMinHeap) Reset() {
+	for _, item := range h.items {
+		h.index[item.ID] = 0
+	}
+	h.items = h.items[0:0]
+}
+
+// Push adds an item with the given ID and priority to the queue. If the queue
+// already contains an item with this ID, its priority is changed instead.
+func (h *IndexedMinHeap) Push(id uint32, prio float32) {
+	if int(id) >= len(h.index) {
+		h.grow(id)
+	}
+
+	if pos := h.index[id]; pos > 0 {
+		i := pos - 1
+		old := h.items[i]
+		h.items[i].Prio = prio
+		if minFirst(&h.items[i], &old) {
+			h.shiftUp(i)
+		} else {
+			h.shiftDown(i)
+		}
+		return
+	}
+
+	h.items = append(h.items, Item{ID: id, Prio: prio})
+	h.index[id] = len(h.items)
+	h.shiftUp(len(h.items) - 1)
+}
+
+// Pop removes the item with the lowest priority value from the queue and
+// returns its ID and priority.
+func (h *IndexedMinHeap) Pop() (id uint32, prio float32) {
+	if len(h.items) == 0 {
+		return 0, 0
+	}
+
+	root := h.items[0]
+	h.removeAt(0)
+	return root.ID, root.Prio
+}
+
+// Remove removes the item with the given ID from the queue. It returns false if
+// there is no such item.
+func (h *IndexedMinHeap) Remove(id uint32) bool {
+	if !h.Contains(id) {
+		return false
+	}
+
+	h.removeAt(h.index[id] - 1)
+	return true
+}
+
+// removeAt removes the item at index i by replacing it with the last item and
+// then restoring the heap property.
+func (h *IndexedMinHeap) removeAt(i int) {
+	removed := h.items[i]
+	h.index[removed.ID] = 0
+
+	maxIndex := len(h.items) - 1
+	last := h.items[maxIndex]
+	h.items = h.items[0:maxIndex]
+	if i == maxIndex {
+		return
+	}
+
+	h.items[i] = last
+	h.index[last.ID] = i + 1
+	if minFirst(&last, &removed) {
+		h.shiftUp(i)
+	} else {
+		h.shiftDown(i)
+	}
+}
+
+// grow increases the size of the index so it contains the given ID.
+func (h *IndexedMinHeap) grow(id uint32) {
+	n := 2 * len(h.index)
+	if n <= int(id) {
+		n = int(id) + 1
+	}
+
+	index := make([]int, n)
+	copy(index, h.index)
+	h.index = index
+}
+
+// shiftUp restores the heap property by moving the item at index i up the
+// binary tree until its parent has a lower priority.
+func (h *IndexedMinHeap) shiftUp(i int) {
+	item := h.items[i]
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !minFirst(&item, &h.items[parent]) {
+			break
+		}
+
+		// move the parent down instead of swapping
+		h.items[i] = h.items[parent]
+		h.index[h.items[i].ID] = i + 1
+		i = parent
+	}
+
+	h.items[i] = item
+	h.index[item.ID] = i + 1
+}
+
+// shiftDown restores the heap property by moving the item at index i down the
+// binary tree until both of its children have a higher priority.
+func (h *IndexedMinHeap) shiftDown(i int) {
+	item := h.items[i]
+	maxIndex := len(h.items) - 1
+	for {
+		j := 2*i + 1 // index of first child of i
+		if j > maxIndex || j < 0 { // j < 0 after int overflow
+			break // item i has no children
+		}
+
+		if j < maxIndex && minFirst(&h.items[j+1], &h.items[j]) {
+			j++
+		}
+
+		if !minFirst(&h.items[j], &item) {
+			break
+		}
+
+		// move the child up instead of swapping
+		h.items[i] = h.items[j]
+		h.index[h.items[i].ID] = i + 1
+		i = j
+	}
+
+	h.items[i] = item
+	h.index[item.ID] = i + 1
+}
@@ -0,0 +1,120 @@
+package prioqueue_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexedMinHeap(t *testing.T) {
+	var h prioqueue.IndexedMinHeap
+
+	id, prio := h.Top()
+	assert.EqualValues(t, 0, id)
+	assert.EqualValues(t, 0, prio)
+	id, prio = h.Pop()
+	assert.EqualValues(t, 0, id)
+	assert.EqualValues(t, 0, prio)
+
+	for i := uint32(1); i <= 10; i++ {
+		h.Push(i, float32(i*10))
+	}
+	require.Equal(t, 10, h.Len())
+	assert.True(t, h.Contains(5))
+	assert.False(t, h.Contains(11))
+	assert.False(t, h.Contains(1000))
+
+	h.Push(7, 5)   // decrease key
+	h.Push(1, 200) // increase key
+	assert.True(t, h.Remove(5))
+	assert.False(t, h.Remove(5))
+	assert.False(t, h.Contains(5))
+	assert.Equal(t, 9, h.Len())
+
+	var popped []uint32
+	for h.Len() > 0 {
+		id, _ := h.Pop()
+		popped = append(popped, id)
+	}
+
+	assert.Equal(t, []uint32{7, 2, 3, 4, 6, 8, 9, 10, 1}, popped)
+	assert.False(t, h.Contains(7))
+}
+
+func TestIndexedMinHeap_Random(t *testing.T) {
+	const n = 1000
+
+	h := prioqueue.NewIndexedMinHeap(10)
+	rng := rand.New(rand.NewSource(42))
+	model := map[uint32]float32{}
+
+	for i := 0; i < 20_000; i++ {
+		id := uint32(rng.Intn(n))
+		switch rng.Intn(4) {
+		case 0, 1:
+			prio := float32(rng.Intn(100))
+			h.Push(id, prio)
+			model[id] = prio
+		case 2:
+			_, ok := model[id]
+			assert.Equal(t, ok, h.Remove(id))
+			delete(model, id)
+		case 3:
+			if len(model) == 0 {
+				continue
+			}
+
+			expected := sortedModel(model)[0]
+			id, prio := h.Pop()
+			assert.Equal(t, expected, prioqueue.Item{ID: id, Prio: prio})
+			delete(model, id)
+		}
+
+		require.Equal(t, len(model), h.Len())
+	}
+
+	for _, expected := range sortedModel(model) {
+		id, prio := h.Pop()
+		assert.Equal(t, expected, prioqueue.Item{ID: id, Prio: prio})
+	}
+	assert.Equal(t, 0, h.Len())
+}
+
+func TestIndexedMinHeap_Reset(t *testing.T) {
+	h := prioqueue.NewIndexedMinHeap(10)
+	for i := uint32(0); i < 10; i++ {
+		h.Push(i, float32(i))
+	}
+
+	h.Reset()
+	assert.Equal(t, 0, h.Len())
+	for i := uint32(0); i < 10; i++ {
+		assert.False(t, h.Contains(i))
+	}
+
+	h.Push(3, 1)
+	h.Push(3, 2)
+	assert.Equal(t, 1, h.Len())
+}
+
+// sortedModel returns the items of the model in the order in which they are
+// expected to be popped from an IndexedMinHeap.
+func sortedModel(model map[uint32]float32) []prioqueue.Item {
+	items := make([]prioqueue.Item, 0, len(model))
+	for id, prio := range model {
+		items = append(items, prioqueue.Item{ID: id, Prio: prio})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Prio != items[j].Prio {
+			return items[i].Prio < items[j].Prio
+		}
+		return items[i].ID < items[j].ID
+	})
+
+	return items
+}
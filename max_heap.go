@@ -2,6 +2,16 @@
 // binary heap encoded in a slice.
 package prioqueue
 
+import "errors"
+
+// ErrEmpty is returned by operations which need at least one item in a queue if
+// the queue is empty.
+var ErrEmpty = errors.New("prioqueue: queue is empty")
+
+// minAutoShrinkCap is the capacity below which backing arrays are never
+// shrunk automatically to avoid reallocating small heaps over and over again.
+const minAutoShrinkCap = 64
+
 // MaxHeap implements a priority queue which allows to retrieve the highest
 // priority element using a heap. Since the heap is maintained in form of a
 // binary tree, it can efficiently be represented in the form of a list.
@@ -26,6 +36,21 @@ package prioqueue
 //   Push and Pop take O(log n) and Top() happens in constant time.
 type MaxHeap struct {
 	items []*Item
+	live  map[uint32]*Item   // a live item of each ID, only maintained once Cancel was used
+	more  map[uint32][]*Item // further live items of IDs which were pushed more than once
+	dead  int                // amount of cancelled items that are still in items
+	free  []*Item            // released items which are reused by Push
+
+	autoShrink  bool
+	tieBreak    bool
+	popStrategy PopStrategy
+	obs         observation
+
+	// hooked is set while Push has to do more than restoring the heap
+	// property, i.e. if the items are indexed for Cancel, ties are broken
+	// by ID or an Observer is set. It is kept up to date by updateHooked so
+	// the default configuration only needs to check a single field.
+	hooked bool
 }
 
 // NewMaxHeap returns a new MaxHeap instance which contains a pre-allocated
@@ -44,7 +69,8 @@ func NewMaxHeap(size int) *MaxHeap {
 }
 
 // Top returns the ID and priority of the item with the highest priority value
-// in the queue without removing it.
+// in the queue without removing it. If the queue is empty, it returns (0, 0).
+// Use TryTop to tell an empty queue apart from an item with ID and priority 0.
 func (h *MaxHeap) Top() (uint32, float32) {
 	i := h.TopItem()
 	if i == nil {
@@ -57,44 +83,130 @@ func (h *MaxHeap) Top() (uint32, float32) {
 // TopItem returns the item with the highest priority value in the queue without
 // removing it.
 func (h *MaxHeap) TopItem() *Item {
+	if h.dead > 0 {
+		h.dropCancelled()
+	}
 	if len(h.items) == 0 {
 		return nil
 	}
 	return h.items[0]
 }
 
-// Len returns the amount of elements in the queue.
+// TryTop returns a copy of the item with the highest priority value in the
+// queue without removing it. It returns false if the queue is empty.
+func (h *MaxHeap) TryTop() (Item, bool) {
+	item := h.TopItem()
+	if item == nil {
+		return Item{}, false
+	}
+	return *item, true
+}
+
+// Len returns the amount of elements in the queue. Cancelled items are not
+// counted, even if they are still held in the backing array.
 func (h *MaxHeap) Len() int {
-	return len(h.items)
+	return len(h.items) - h.dead
 }
 
-// Reset is a fast way to empty the queue. Note that the underlying array will
-// still be used by the heap which means that this function will not free up any
-// memory. If you need to release memory, you have to create a new instance and
-// let this one be taken care of by the garbage collection.
+// Reset is a fast way to empty the queue. The slots of the backing array are
+// cleared so the garbage collector can reclaim the items, but the array itself
+// will still be used by the heap which means that its memory is not freed. If
+// you need to release it, you can call Shrink afterwards or create a new
+// instance and let this one be taken care of by the garbage collection.
 func (h *MaxHeap) Reset() {
+	for i := range h.items {
+		h.items[i] = nil
+	}
 	h.items = h.items[0:0]
+	h.dead = 0
+	h.live, h.more = nil, nil
+	h.updateHooked()
+
+	if h.obs.observer != nil {
+		h.obs.reset()
+	}
 }
 
 // Items returns all elements that are currently in the queue.
 // The caller should ignore the order in which elements are returned since this
-// only reflects how the queue stores its items internally.
+// only reflects how the queue stores its items internally. The returned slice
+// may also contain items which have been cancelled but not yet removed.
 func (h *MaxHeap) Items() []*Item {
 	return h.items
 }
 
 // PopAndPush removes the item with the highest priority value and adds a new
 // value to the heap in one operation. This is faster than two separate calls
-// to Pop and Push.
+// to Pop and Push. If the queue is empty, the item is simply pushed.
 func (h *MaxHeap) PopAndPush(item *Item) {
-	h.items[0] = item
-	h.shiftDown()
+	h.Replace(item)
+}
+
+// Replace removes the item with the highest priority value, adds the new item
+// to the heap in one operation and returns the removed item. If the queue is
+// empty, the new item is simply pushed and Replace returns false.
+func (h *MaxHeap) Replace(item *Item) (old *Item, ok bool) {
+	if h.dead > 0 {
+		h.dropCancelled()
+	}
+	if len(h.items) == 0 {
+		h.PushItem(item)
+		return nil, false
+	}
+
+	old = h.items[0]
+	h.replaceRoot(item)
+	if h.live != nil {
+		h.unindex(old)
+		h.index(item)
+	}
+
+	if h.obs.observer != nil {
+		h.obs.replaced(old, item, h.Len(), cap(h.items))
+	}
+
+	return old, true
 }
 
 // Push the value item into the priority queue with provided priority.
+//
+// If items have been handed back to the queue via Release, Push reuses them
+// instead of allocating a new Item.
 func (h *MaxHeap) Push(id uint32, prio float32) {
-	item := &Item{ID: id, Prio: prio}
-	h.PushItem(item)
+	var item *Item
+	if n := len(h.free); n > 0 {
+		item = h.free[n-1]
+		h.free[n-1] = nil
+		h.free = h.free[:n-1]
+		item.ID, item.Prio = id, prio
+	} else {
+		item = &Item{ID: id, Prio: prio}
+	}
+
+	// This is the same as PushItem, which is not called here since the extra
+	// call is noticeable in the benchmarks.
+	h.items = append(h.items, item)
+	if h.hooked {
+		h.pushHooked(item)
+		return
+	}
+	h.shiftUp(len(h.items) - 1)
+}
+
+// Release hands an item which was returned by PopItem back to the queue so it
+// can be reused by a later call to Push. This avoids allocating a new Item for
+// every Push if items are pushed and popped continuously. The caller must not
+// use the item anymore after it has been released.
+//
+// Releasing nil has no effect, so the result of PopItem can be released even if
+// the queue was empty. The queue keeps at most as many released items as fit
+// into its backing array. Any further items are left to the garbage collector.
+func (h *MaxHeap) Release(item *Item) {
+	if item == nil || len(h.free) >= cap(h.items) {
+		return
+	}
+
+	h.free = append(h.free, item)
 }
 
 // PushItem adds an Item to the queue.
@@ -102,27 +214,104 @@ func (h *MaxHeap) PushItem(item *Item) {
 	// Add new item to the end of the list and then let it bubble up the binary
 	// tree until the heap property is restored.
 	h.items = append(h.items, item)
+	if h.hooked {
+		h.pushHooked(item)
+		return
+	}
+	h.shiftUp(len(h.items) - 1)
+}
 
-	i := len(h.items) - 1 // start at the last element
-	for i > 0 {
-		parent := (i - 1) / 2
-		if h.items[parent].Prio >= h.items[i].Prio {
-			// heap property is now satisfied again
-			return
+// pushHooked restores the heap property after the item was appended to the
+// backing array and takes care of the index, the tie-breaking and the
+// Observer.
+func (h *MaxHeap) pushHooked(item *Item) {
+	if h.live != nil {
+		h.index(item)
+	}
+	if h.tieBreak {
+		h.shiftUpByID(len(h.items) - 1)
+	} else {
+		h.shiftUp(len(h.items) - 1)
+	}
+
+	if h.obs.observer != nil {
+		h.obs.pushed(item, h.Len(), cap(h.items))
+	}
+}
+
+// PushMany adds copies of all given items to the queue. This is faster than
+// calling Push for each of them since the backing array grows at most once and
+// the new items are either reused from the items passed to Release or
+// allocated in a single block. Note that such a block is only freed once none
+// of its items is referenced anymore.
+//
+// If the batch contains more items than the queue, the items are appended to
+// the backing array and the heap is rebuilt in linear time instead of pushing
+// each item individually.
+func (h *MaxHeap) PushMany(items []Item) {
+	n := len(h.items)
+	if need := n + len(items); need > cap(h.items) {
+		c := 2 * cap(h.items)
+		if c < need {
+			c = need
 		}
 
-		h.items[i], h.items[parent] = h.items[parent], h.items[i]
-		i = parent
+		grown := make([]*Item, n, c)
+		copy(grown, h.items)
+		h.items = grown
+	}
+
+	var block []Item
+	if k := len(items) - len(h.free); k > 0 {
+		block = make([]Item, k)
+	}
+
+	size := h.Len()
+	rebuild := len(items) > size
+	for _, it := range items {
+		var item *Item
+		if m := len(h.free); m > 0 {
+			item = h.free[m-1]
+			h.free[m-1] = nil
+			h.free = h.free[:m-1]
+		} else {
+			item, block = &block[0], block[1:]
+		}
+
+		*item = it
+		if rebuild {
+			h.items = append(h.items, item)
+			h.index(item)
+		} else {
+			h.PushItem(item)
+		}
+	}
+
+	if !rebuild {
+		return
+	}
+
+	if h.obs.observer == nil {
+		h.heapify()
+		return
+	}
+
+	// The observer is notified once the heap property is restored.
+	added := append([]*Item(nil), h.items[n:]...)
+	h.heapify()
+	for i, item := range added {
+		h.obs.pushed(item, size+i+1, cap(h.items))
 	}
 }
 
 // Pop removes the item with the highest priority value from the queue and
-// returns its ID and priority.
+// returns its ID and priority. If the queue is empty, it returns (0, 0). Use
+// TryPop to tell an empty queue apart from an item with ID and priority 0.
 //
 // Note that while popping an element from the heap will also remove it from the
 // queue but it will not release the memory in the backing array as long as the
-// heap is still in use.
-// See https://blog.golang.org/slices-intro#TOC_6.
+// heap is still in use, unless automatic shrinking was enabled via
+// SetAutoShrink. See https://blog.golang.org/slices-intro#TOC_6.
 func (h *MaxHeap) Pop() (id uint32, priority float32) {
 	i := h.PopItem()
 	if i == nil {
@@ -134,28 +323,376 @@ func (h *MaxHeap) Pop() (id uint32, priority float32) {
 
 // PopItem removes the item with the highest priority value from the queue.
 func (h *MaxHeap) PopItem() *Item {
+	if h.dead > 0 {
+		h.dropCancelled()
+	}
 	if len(h.items) == 0 {
 		return nil
 	}
 
+	item := h.removeRoot()
+	if h.live != nil {
+		h.unindex(item)
+	}
+	if h.obs.observer != nil {
+		h.obs.popped(item, h.Len(), cap(h.items))
+	}
+
+	return item
+}
+
+// TryPop removes the item with the highest priority value from the queue and
+// returns a copy of it. It returns false if the queue is empty.
+func (h *MaxHeap) TryPop() (Item, bool) {
+	item := h.PopItem()
+	if item == nil {
+		return Item{}, false
+	}
+	return *item, true
+}
+
+// PopN removes up to n items with the highest priority values from the queue
+// and appends them to dst in the order in which they were removed. It returns
+// the extended slice. If dst has enough capacity, PopN does not allocate.
+func (h *MaxHeap) PopN(n int, dst []*Item) []*Item {
+	for ; n > 0; n-- {
+		item := h.PopItem()
+		if item == nil {
+			break
+		}
+		dst = append(dst, item)
+	}
+
+	return dst
+}
+
+// Cancel marks all items with the given ID as deleted. The items are not
+// actually removed from the heap. Instead they stay in the backing array as
+// tombstones and are skipped once they reach the root of the tree. As soon as
+// there are more tombstones than live items in the queue, all tombstones are
+// removed at once and the heap is rebuilt.
+//
+// Only the items which are in the queue when Cancel is called are affected.
+// Items which are pushed afterwards are live, even if they have the same ID.
+// Cancelling an ID which is not in the queue has no effect.
+//
+// In order to find the items of an ID, the heap builds an index of its items
+// when Cancel is called on a queue without an index, which takes O(n). From
+// then on, Push and Pop keep the index up to date and Cancel takes O(1) time,
+// or O(k) if there are k items with the given ID. The index is dropped once
+// the queue is empty or Reset, so queues which never use Cancel do not pay
+// for it.
+func (h *MaxHeap) Cancel(id uint32) {
+	if h.live == nil {
+		h.live = make(map[uint32]*Item, len(h.items))
+		for _, item := range h.items {
+			h.index(item)
+		}
+		h.updateHooked()
+	}
+
+	if _, ok := h.live[id]; !ok {
+		return
+	}
+
+	h.dead += 1 + len(h.more[id])
+	delete(h.live, id)
+	delete(h.more, id)
+	if h.obs.observer != nil {
+		h.obs.cancelled(h.Len())
+	}
+
+	if h.dead > h.Len() {
+		h.compact()
+	}
+}
+
+// Shrink releases unused memory by copying all items into a new backing array
+// which is exactly as large as the queue. Cancelled items are dropped as well
+// and all items which have been passed to Release are freed.
+// This is useful after the queue has been drained or Reset following a burst of
+// many items.
+func (h *MaxHeap) Shrink() {
+	h.free = nil
+
+	if h.dead > 0 {
+		h.compact()
+	}
+
+	if len(h.items) == 0 {
+		h.items = nil
+	} else {
+		items := make([]*Item, len(h.items))
+		copy(items, h.items)
+		h.items = items
+	}
+
+	if h.obs.observer != nil {
+		h.obs.observer.OnShrink(h.Len(), cap(h.items))
+	}
+}
+
+// SetAutoShrink enables or disables the automatic shrinking of the backing
+// array. If enabled, the capacity of the backing array is halved whenever
+// popping an item makes the queue use less than a quarter of it. Small arrays
+// are never shrunk automatically. Automatic shrinking is disabled by default.
+func (h *MaxHeap) SetAutoShrink(enabled bool) {
+	h.autoShrink = enabled
+}
+
+// SetObserver sets an Observer which is notified about all operations on the
+// queue. The Observer's OnHighWaterMark method is called when the length of the
+// queue reaches the given high-water mark. Zero or a negative value disables
+// the high-water mark. Passing a nil Observer removes the current Observer.
+func (h *MaxHeap) SetObserver(o Observer, highWaterMark int) {
+	h.obs.set(o, highWaterMark, h.Len())
+	h.updateHooked()
+}
+
+// SetTieBreakByID defines whether items with equal priority are dequeued in
+// ascending order of their IDs. If IDs are assigned sequentially, this makes
+// the queue return items with equal priority in the order in which they were
+// pushed. Tie-breaking is disabled by default, in which case the order of
+// items with equal priority is unspecified. It must only be changed while the
+// queue is empty.
+func (h *MaxHeap) SetTieBreakByID(enabled bool) {
+	h.tieBreak = enabled
+	h.updateHooked()
+}
+
+// SetPopStrategy defines how the heap property is restored after the root node
+// was removed by Pop or replaced by PopAndPush. The default is TopDown.
+func (h *MaxHeap) SetPopStrategy(s PopStrategy) {
+	h.popStrategy = s
+}
+
+// removeRoot removes the root node from the non-empty heap and returns it.
+func (h *MaxHeap) removeRoot() *Item {
 	root := h.items[0]
 	maxIndex := len(h.items) - 1
+	last := h.items[maxIndex]
 
-	// swap first and last element and then remove the last from the list
-	h.items[0], h.items[maxIndex] = h.items[maxIndex], h.items[0]
+	// remove the last element from the end of the list so it can be moved to
+	// the root. The slot is cleared so the garbage collector can free the item
+	// once the caller is done with it.
+	h.items[maxIndex] = nil
 	h.items = h.items[0:maxIndex]
 
-	// restore heap property
-	h.shiftDown()
+	if h.autoShrink {
+		h.shrinkIfSparse()
+	}
+
+	// restore heap property. The default configuration is handled here to
+	// save a call on every pop.
+	if maxIndex > 0 {
+		if h.popStrategy == TopDown && !h.tieBreak {
+			h.items[0] = last
+			h.shiftDown(0)
+		} else {
+			h.replaceRoot(last)
+		}
+	}
 
 	return root
 }
 
-// shiftDown restores the heap property by shifting down the root node in the binary
-// tree until the heap property is satisfied.
-func (h *MaxHeap) shiftDown() {
-	maxIndex := len(h.items) - 1
-	i := 0 // start at the root node
+// replaceRoot replaces the root node of the non-empty heap with the given item
+// and restores the heap property using the configured PopStrategy.
+func (h *MaxHeap) replaceRoot(item *Item) {
+	switch {
+	case h.popStrategy == BottomUp:
+		h.bounce(item)
+	case h.tieBreak:
+		h.items[0] = item
+		h.shiftDownByID(0)
+	default:
+		h.items[0] = item
+		h.shiftDown(0)
+	}
+}
+
+// shrinkIfSparse halves the capacity of the backing array if less than a
+// quarter of it is in use.
+func (h *MaxHeap) shrinkIfSparse() {
+	c := cap(h.items)
+	if c <= minAutoShrinkCap || len(h.items) >= c/4 {
+		return
+	}
+
+	items := make([]*Item, len(h.items), c/2)
+	copy(items, h.items)
+	h.items = items
+
+	if h.obs.observer != nil {
+		h.obs.observer.OnShrink(h.Len(), cap(h.items))
+	}
+}
+
+// dropCancelled removes cancelled items from the root of the heap until the
+// root is a live item again or the heap is empty.
+func (h *MaxHeap) dropCancelled() {
+	for h.dead > 0 && len(h.items) > 0 && h.isDead(h.items[0]) {
+		h.dead-- // before removeRoot so Len is correct if the heap shrinks
+		h.removeRoot()
+	}
+}
+
+// compact removes all cancelled items from the backing array and then restores
+// the heap property for the remaining items.
+func (h *MaxHeap) compact() {
+	live := h.items[:0]
+	for _, item := range h.items {
+		if !h.isDead(item) {
+			live = append(live, item)
+		}
+	}
+
+	// clear the remaining slots so the garbage collector can free the items
+	for i := len(live); i < len(h.items); i++ {
+		h.items[i] = nil
+	}
+
+	h.items = live
+	h.dead = 0
+	h.heapify()
+}
+
+// updateHooked updates the hooked field after the index, the tie-breaking or
+// the Observer changed.
+func (h *MaxHeap) updateHooked() {
+	h.hooked = h.live != nil || h.tieBreak || h.obs.observer != nil
+}
+
+// isDead returns true if the item has been cancelled. It must only be called
+// once the index of live items has been built by Cancel.
+func (h *MaxHeap) isDead(item *Item) bool {
+	if h.live[item.ID] == item {
+		return false
+	}
+
+	for _, x := range h.more[item.ID] {
+		if x == item {
+			return false
+		}
+	}
+	return true
+}
+
+// index adds the item to the index of live items if Cancel has been used.
+// Only the items of IDs which are in the queue more than once need to be
+// stored in a slice, so indexing does not allocate in the common case.
+func (h *MaxHeap) index(item *Item) {
+	if h.live == nil {
+		return
+	}
+
+	if _, ok := h.live[item.ID]; !ok {
+		h.live[item.ID] = item
+		return
+	}
+
+	if h.more == nil {
+		h.more = map[uint32][]*Item{}
+	}
+	h.more[item.ID] = append(h.more[item.ID], item)
+}
+
+// unindex removes the item from the index of live items if Cancel has been
+// used. The index is dropped as soon as the queue is empty.
+func (h *MaxHeap) unindex(item *Item) {
+	if h.live == nil {
+		return
+	}
+
+	if len(h.items) == 0 {
+		h.live, h.more = nil, nil
+		h.updateHooked()
+		return
+	}
+
+	more := h.more[item.ID]
+	if h.live[item.ID] == item {
+		if len(more) == 0 {
+			delete(h.live, item.ID)
+			return
+		}
+
+		// Another item with the same ID takes its place.
+		h.live[item.ID] = more[len(more)-1]
+		more[len(more)-1] = nil
+		h.setMore(item.ID, more[:len(more)-1])
+		return
+	}
+
+	for i, x := range more {
+		if x == item {
+			more[i] = more[len(more)-1]
+			more[len(more)-1] = nil
+			h.setMore(item.ID, more[:len(more)-1])
+			return
+		}
+	}
+}
+
+// setMore stores the further live items of the given ID.
+func (h *MaxHeap) setMore(id uint32, items []*Item) {
+	if len(items) == 0 {
+		delete(h.more, id)
+		return
+	}
+	h.more[id] = items
+}
+
+// heapify establishes the heap property for all items in the backing array by
+// shifting down all nodes which have children, starting at the last of them.
+func (h *MaxHeap) heapify() {
+	for i := len(h.items)/2 - 1; i >= 0; i-- {
+		if h.tieBreak {
+			h.shiftDownByID(i)
+		} else {
+			h.shiftDown(i)
+		}
+	}
+}
+
+// shiftUp restores the heap property by moving the node at index i up the
+// binary tree until its parent is dequeued before it.
+func (h *MaxHeap) shiftUp(i int) {
+	items := h.items
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !higherPrio(items[i], items[parent]) {
+			// heap property is now satisfied again
+			break
+		}
+
+		items[i], items[parent] = items[parent], items[i]
+		i = parent
+	}
+}
+
+// shiftUpByID is like shiftUp but breaks ties in priority by ID.
+func (h *MaxHeap) shiftUpByID(i int) {
+	items := h.items
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !maxFirst(items[i], items[parent]) {
+			break
+		}
+
+		items[i], items[parent] = items[parent], items[i]
+		i = parent
+	}
+}
+
+// shiftDown restores the heap property by shifting down the node at index i in
+// the binary tree until the heap property is satisfied. Instead of swapping the
+// node with its children, the children are moved up and the node is only
+// written once at its final position.
+func (h *MaxHeap) shiftDown(i int) {
+	items := h.items
+	item := items[i]
+	maxIndex := len(items) - 1
 	for {
 		j := 2*i + 1 // index of first child of i
 
@@ -163,19 +700,112 @@ func (h *MaxHeap) shiftDown() {
 			break // item i has no children
 		}
 
-		if j < maxIndex && h.items[j].Prio < h.items[j+1].Prio {
+		if j < maxIndex && higherPrio(items[j+1], items[j]) {
 			j++
 		}
 
-		if h.items[i].Prio >= h.items[j].Prio {
+		if !higherPrio(items[j], item) {
 			// heap property is now satisfied again
 			break
 		}
 
-		// swap parent and child
-		h.items[i], h.items[j] = h.items[j], h.items[i]
+		// move the child up and continue at the child node
+		items[i] = items[j]
+		i = j
+	}
+
+	items[i] = item
+}
 
-		// continue at child node
+// shiftDownByID is like shiftDown but breaks ties in priority by ID. It is a
+// separate loop since the additional comparison of the IDs would make the
+// default configuration measurably slower, even if tie-breaking is disabled.
+func (h *MaxHeap) shiftDownByID(i int) {
+	items := h.items
+	item := items[i]
+	maxIndex := len(items) - 1
+	for {
+		j := 2*i + 1
+		if j > maxIndex || j < 0 {
+			break
+		}
+
+		if j < maxIndex && maxFirst(items[j+1], items[j]) {
+			j++
+		}
+
+		if !maxFirst(items[j], item) {
+			break
+		}
+
+		items[i] = items[j]
 		i = j
 	}
+
+	items[i] = item
+}
+
+// bounce places the item at the root of the non-empty heap using the BottomUp
+// PopStrategy. First the hole at the root is moved down to a leaf by moving the
+// first child up at every level. Then the item is moved up from the leaf until
+// the heap property is satisfied.
+func (h *MaxHeap) bounce(item *Item) {
+	items, tieBreak := h.items, h.tieBreak
+	i := 0
+	maxIndex := len(items) - 1
+	for {
+		j := 2*i + 1 // index of first child of i
+
+		if j > maxIndex || j < 0 { // j < 0 after int overflow
+			break // the hole is a leaf now
+		}
+
+		if j < maxIndex && maxHeapFirst(items[j+1], items[j], tieBreak) {
+			j++
+		}
+
+		items[i] = items[j]
+		i = j
+	}
+
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !maxHeapFirst(item, items[parent], tieBreak) {
+			break
+		}
+
+		items[i] = items[parent]
+		i = parent
+	}
+
+	items[i] = item
+}
+
+// maxHeapFirst returns true if item a must be dequeued before item b from a MaxHeap.
+// Ties in priority are only broken by ID if tieBreak is set, which is enabled
+// via SetTieBreakByID.
+func maxHeapFirst(a, b *Item, tieBreak bool) bool {
+	if tieBreak {
+		return maxFirst(a, b)
+	}
+	return higherPrio(a, b)
+}
+
+// higherPrio returns true if item a has a higher priority than item b.
+func higherPrio(a, b *Item) bool {
+	if countComparisons {
+		comparisons++
+	}
+	return a.Prio > b.Prio
+}
+
+// maxFirst returns true if item a must be dequeued before item b from a queue
+// which returns the items with the highest priority first, such as the BHeap
+// or a MaxHeap with tie-breaking by ID. Ties in priority are broken by the ID
+// of the items so the order in which items are dequeued is deterministic.
+func maxFirst(a, b *Item) bool {
+	if countComparisons {
+		comparisons++
+	}
+	return a.Prio > b.Prio || (a.Prio == b.Prio && a.ID < b.ID)
 }
@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/fgrosse/prioqueue"
+	"github.com/fgrosse/prioqueue/prioqueuetest"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMaxHeap(t *testing.T) {
@@ -20,3 +22,387 @@ func TestMaxHeap_Random(t *testing.T) {
 	pq := prioqueue.NewMaxHeap(10)
 	runTestsN(t, pq, assertBiggestFirst, 10_000)
 }
+
+func TestMaxHeap_Conformance(t *testing.T) {
+	prioqueuetest.Run(t, func() prioqueuetest.PriorityQueue {
+		return new(prioqueue.MaxHeap)
+	}, prioqueuetest.MaxFirst)
+}
+
+func TestMaxHeap_BottomUp(t *testing.T) {
+	prioqueuetest.Run(t, func() prioqueuetest.PriorityQueue {
+		h := prioqueue.NewMaxHeap(0)
+		h.SetPopStrategy(prioqueue.BottomUp)
+		return h
+	}, prioqueuetest.MaxFirst)
+}
+
+func TestMaxHeap_Cancel(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(10)
+	for i := uint32(1); i <= 10; i++ {
+		pq.Push(i, float32(i*10))
+	}
+
+	pq.Cancel(10) // current top
+	pq.Cancel(5)
+	pq.Cancel(1)
+	assert.Equal(t, 7, pq.Len())
+
+	id, prio := pq.Top()
+	assert.EqualValues(t, 9, id)
+	assert.EqualValues(t, 90, prio)
+
+	var popped []uint32
+	for pq.Len() > 0 {
+		id, _ := pq.Pop()
+		popped = append(popped, id)
+	}
+
+	assert.Equal(t, []uint32{9, 8, 7, 6, 4, 3, 2}, popped)
+	assert.Nil(t, pq.PopItem())
+}
+
+func TestMaxHeap_CancelRepush(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(10)
+	pq.Push(1, 1)
+	pq.Push(2, 5)
+	pq.Cancel(1)
+	pq.Push(1, 10) // reschedule the cancelled ID
+	assert.Equal(t, 2, pq.Len())
+
+	id, prio := pq.Pop()
+	assert.EqualValues(t, 1, id)
+	assert.EqualValues(t, 10, prio)
+
+	id, prio = pq.Pop()
+	assert.EqualValues(t, 2, id)
+	assert.EqualValues(t, 5, prio)
+
+	assert.Nil(t, pq.PopItem(), "the cancelled item should not come back")
+	assert.Equal(t, 0, pq.Len())
+}
+
+func TestMaxHeap_CancelDuplicates(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(10)
+	pq.Push(1, 30)
+	pq.Push(1, 10)
+	pq.Push(2, 20)
+	pq.Push(3, 5)
+
+	pq.Cancel(4) // not in the queue
+	assert.Equal(t, 4, pq.Len())
+
+	pq.Cancel(1)
+	assert.Equal(t, 2, pq.Len())
+	pq.Cancel(1) // cancelling twice has no effect
+	assert.Equal(t, 2, pq.Len())
+
+	var popped []uint32
+	for pq.Len() > 0 {
+		id, _ := pq.Pop()
+		popped = append(popped, id)
+	}
+	assert.Equal(t, []uint32{2, 3}, popped, "all copies of ID 1 should be cancelled")
+	assert.Nil(t, pq.PopItem())
+}
+
+func TestMaxHeap_CancelAllocs(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(100)
+	for i := uint32(0); i < 100; i++ {
+		pq.Push(i, float32(i))
+	}
+	pq.Cancel(50)
+
+	// Keeping track of the items for Cancel must not cost an allocation on
+	// every operation.
+	id := uint32(100)
+	allocs := testing.AllocsPerRun(1000, func() {
+		pq.Release(pq.PopItem())
+		pq.Push(id, float32(id%100))
+		id++
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestMaxHeap_CancelCompaction(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(10)
+	for i := uint32(1); i <= 10; i++ {
+		pq.Push(i, float32(i))
+	}
+
+	for i := uint32(1); i <= 5; i++ {
+		pq.Cancel(i)
+	}
+	assert.Equal(t, 5, pq.Len())
+	assert.Len(t, pq.Items(), 10, "tombstones should not yet be compacted")
+
+	pq.Cancel(6)
+	assert.Equal(t, 4, pq.Len())
+	assert.Len(t, pq.Items(), 4, "tombstones should have been compacted")
+
+	var popped []uint32
+	for pq.Len() > 0 {
+		id, _ := pq.Pop()
+		popped = append(popped, id)
+	}
+	assert.Equal(t, []uint32{10, 9, 8, 7}, popped)
+
+	runTestsN(t, pq, assertBiggestFirst, 1000)
+}
+
+func TestMaxHeap_Shrink(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(1000)
+	for i := uint32(0); i < 1000; i++ {
+		pq.Push(i, float32(i))
+	}
+	for i := 0; i < 990; i++ {
+		pq.Pop()
+	}
+
+	assert.Equal(t, 1000, cap(pq.Items()))
+	pq.Shrink()
+	assert.Equal(t, 10, pq.Len())
+	assert.Equal(t, 10, cap(pq.Items()))
+
+	pq.Reset()
+	pq.Shrink()
+	assert.Equal(t, 0, cap(pq.Items()))
+
+	runTestsN(t, pq, assertBiggestFirst, 1000)
+}
+
+func TestMaxHeap_AutoShrink(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(1024)
+	pq.SetAutoShrink(true)
+	for i := uint32(0); i < 1024; i++ {
+		pq.Push(i, float32(i))
+	}
+
+	for pq.Len() > 256 {
+		pq.Pop()
+	}
+	assert.Equal(t, 1024, cap(pq.Items()))
+
+	pq.Pop()
+	assert.Equal(t, 255, pq.Len())
+	assert.Equal(t, 512, cap(pq.Items()))
+
+	for pq.Len() > 0 {
+		pq.Pop()
+	}
+	assert.Equal(t, 64, cap(pq.Items()), "small arrays should not be shrunk")
+}
+
+func TestMaxHeap_PopClearsSlot(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(2)
+	pq.Push(1, 1)
+	pq.Push(2, 2)
+	pq.Pop()
+
+	items := pq.Items()
+	assert.Len(t, items, 1)
+	assert.Nil(t, items[:2][1], "popped slot should be cleared")
+}
+
+func TestMaxHeap_ResetClearsSlots(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(2)
+	pq.Push(1, 1)
+	pq.Push(2, 2)
+	pq.Reset()
+
+	items := pq.Items()[:2]
+	assert.Nil(t, items[0], "reset slots should be cleared")
+	assert.Nil(t, items[1], "reset slots should be cleared")
+}
+
+func TestMaxHeap_Release(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(10)
+	pq.Push(1, 10)
+	pq.Push(2, 20)
+
+	item := pq.PopItem()
+	pq.Release(item)
+	pq.Push(3, 30)
+	assert.Same(t, item, pq.TopItem(), "released item should be reused")
+	assert.EqualValues(t, 3, item.ID)
+	assert.EqualValues(t, 30, item.Prio)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		pq.Release(pq.PopItem())
+		pq.Push(4, 40)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestMaxHeap_ReleaseNil(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(0)
+	pq.Release(pq.PopItem()) // the queue is empty
+	pq.Push(1, 1)
+	assert.Equal(t, 1, pq.Len())
+}
+
+func TestMaxHeap_ReleaseLimit(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(2)
+	released := map[*prioqueue.Item]bool{}
+	for i := 0; i < 10; i++ {
+		item := new(prioqueue.Item)
+		released[item] = true
+		pq.Release(item)
+	}
+
+	pq.Push(1, 1)
+	pq.Push(2, 2)
+	pq.Push(3, 3)
+
+	var reused int
+	for _, item := range pq.Items() {
+		if released[item] {
+			reused++
+		}
+	}
+	assert.Equal(t, 2, reused, "only as many items as fit into the backing array should be kept")
+}
+
+func TestMaxHeap_TieBreakByID(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(10)
+	pq.SetTieBreakByID(true)
+	for _, id := range []uint32{5, 3, 9, 1, 7} {
+		pq.Push(id, 42)
+	}
+
+	var popped []uint32
+	for pq.Len() > 0 {
+		id, _ := pq.Pop()
+		popped = append(popped, id)
+	}
+
+	assert.Equal(t, []uint32{1, 3, 5, 7, 9}, popped, "ties should be broken by ID")
+}
+
+func TestMaxHeap_PushMany(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(0)
+	pq.Push(100, 5)
+
+	// The first batch is larger than the heap, so the heap is rebuilt. The
+	// second batch is pushed item by item.
+	pq.PushMany([]prioqueue.Item{{ID: 1, Prio: 3}, {ID: 2, Prio: 9}, {ID: 3, Prio: 1}})
+	pq.PushMany([]prioqueue.Item{{ID: 4, Prio: 7}})
+	pq.PushMany(nil)
+	assert.Equal(t, 5, pq.Len())
+
+	var popped []uint32
+	for _, item := range pq.PopN(10, nil) {
+		popped = append(popped, item.ID)
+	}
+	assert.Equal(t, []uint32{2, 4, 100, 1, 3}, popped)
+
+	checkRandomOps(t, pq, prioqueuetest.MaxFirst)
+}
+
+func TestMaxHeap_PushManyRelease(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(0)
+	pq.Push(1, 1)
+	released := pq.PopItem()
+	pq.Release(released)
+
+	pq.PushMany([]prioqueue.Item{{ID: 2, Prio: 2}, {ID: 3, Prio: 3}})
+	assert.Contains(t, pq.Items(), released, "released item should be reused")
+
+	batch := make([]prioqueue.Item, 1000)
+	for i := range batch {
+		batch[i] = prioqueue.Item{ID: uint32(i), Prio: float32(i)}
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		pq.Reset()
+		pq.PushMany(batch)
+	})
+	assert.EqualValues(t, 1, allocs, "the items should be allocated in a single block")
+}
+
+func TestMaxHeap_PopN(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(0)
+	for i := uint32(1); i <= 5; i++ {
+		pq.Push(i, float32(i))
+	}
+
+	dst := make([]*prioqueue.Item, 0, 5)
+	dst = pq.PopN(2, dst)
+	dst = pq.PopN(0, dst)
+	assert.Len(t, dst, 2)
+	assert.EqualValues(t, 5, dst[0].ID)
+	assert.EqualValues(t, 4, dst[1].ID)
+	assert.Equal(t, 3, pq.Len())
+
+	allocs := testing.AllocsPerRun(10, func() {
+		pq.Push(1, 1)
+		dst = pq.PopN(1, dst[:0])
+		pq.Release(dst[0])
+	})
+	assert.Zero(t, allocs, "PopN should not allocate if dst is large enough")
+
+	dst = pq.PopN(10, dst[:0])
+	assert.Len(t, dst, 3)
+	assert.Equal(t, 0, pq.Len())
+}
+
+func TestMaxHeap_TryPop(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(0)
+
+	_, ok := pq.TryTop()
+	assert.False(t, ok)
+	_, ok = pq.TryPop()
+	assert.False(t, ok)
+
+	// An item with ID 0 and priority 0 can be told apart from an empty queue.
+	pq.Push(0, 0)
+	item, ok := pq.TryTop()
+	assert.True(t, ok)
+	assert.Equal(t, prioqueue.Item{}, item)
+
+	item, ok = pq.TryPop()
+	assert.True(t, ok)
+	assert.Equal(t, prioqueue.Item{}, item)
+	assert.Equal(t, 0, pq.Len())
+}
+
+func TestMaxHeap_PopAndPushEmpty(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(0)
+
+	// PopAndPush on an empty queue pushes the item instead of panicking.
+	pq.PopAndPush(&prioqueue.Item{ID: 1, Prio: 10})
+	assert.Equal(t, 1, pq.Len())
+
+	id, prio := pq.Pop()
+	assert.EqualValues(t, 1, id)
+	assert.EqualValues(t, 10, prio)
+
+	// The same applies if the queue only contains cancelled items.
+	pq.Push(2, 20)
+	pq.Cancel(2)
+	pq.PopAndPush(&prioqueue.Item{ID: 3, Prio: 30})
+	assert.Equal(t, 1, pq.Len())
+
+	id, prio = pq.Pop()
+	assert.EqualValues(t, 3, id)
+	assert.EqualValues(t, 30, prio)
+}
+
+func TestMaxHeap_Replace(t *testing.T) {
+	pq := prioqueue.NewMaxHeap(0)
+
+	first := &prioqueue.Item{ID: 1, Prio: 10}
+	old, ok := pq.Replace(first)
+	assert.False(t, ok, "replacing on an empty queue should push the item")
+	assert.Nil(t, old)
+	assert.Equal(t, 1, pq.Len())
+
+	pq.Push(2, 5)
+	old, ok = pq.Replace(&prioqueue.Item{ID: 3, Prio: 1})
+	assert.True(t, ok)
+	assert.Same(t, first, old)
+	assert.Equal(t, 2, pq.Len())
+
+	id, _ := pq.Top()
+	assert.EqualValues(t, 2, id)
+}
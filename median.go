@@ -0,0 +1,136 @@
+package prioqueue
+
+// RunningMedian computes the median of a stream of values. It keeps the lower
+// half of all values in a MaxHeap and the upper half in a MinHeap so the median
+// can always be read from the top of the two heaps.
+//
+// Values can be removed again via the ID that is returned when they are added.
+// This makes it possible to compute the median over a sliding window, by
+// removing the oldest value whenever a new value is added.
+//
+// The zero value of a RunningMedian is ready to use.
+//
+// Time Complexity
+//
+//   Add and Remove take amortized O(log n) and Median happens in constant time.
+type RunningMedian struct {
+	heaps  splitHeaps
+	nextID uint32
+}
+
+// NewRunningMedian returns a new RunningMedian instance.
+func NewRunningMedian() *RunningMedian {
+	return new(RunningMedian)
+}
+
+// Add adds a value and returns an ID which can be used to remove it again.
+// IDs are assigned sequentially starting at 0 and wrap around after 2^32 values,
+// so a value must be removed before the same ID is returned again.
+func (m *RunningMedian) Add(value float32) uint32 {
+	id := m.nextID
+	m.nextID++
+
+	m.heaps.add(id, value)
+	m.rebalance()
+	return id
+}
+
+// Remove removes the value with the given ID. Unknown IDs are ignored.
+func (m *RunningMedian) Remove(id uint32) {
+	if m.heaps.remove(id) {
+		m.rebalance()
+	}
+}
+
+// Median returns the median of all values. If there is an even amount of
+// values, the median is the mean of the two values in the middle. If there are
+// no values, Median returns 0.
+func (m *RunningMedian) Median() float32 {
+	if m.heaps.lower.Len() == 0 {
+		return 0
+	}
+
+	_, lowerMax := m.heaps.lower.Top()
+	if m.heaps.lower.Len() > m.heaps.upper.Len() {
+		return lowerMax
+	}
+
+	_, upperMin := m.heaps.upper.Top()
+	return float32((float64(lowerMax) + float64(upperMin)) / 2)
+}
+
+// Len returns the amount of values.
+func (m *RunningMedian) Len() int {
+	return m.heaps.len()
+}
+
+// rebalance moves values between the two heaps until the lower heap contains
+// either as many values as the upper heap or exactly one more.
+func (m *RunningMedian) rebalance() {
+	m.heaps.rebalance((m.heaps.len() + 1) / 2)
+}
+
+// splitHeaps partitions a set of values into a lower and an upper part such
+// that no value in the lower part is larger than any value in the upper part.
+type splitHeaps struct {
+	lower MaxHeap // lower part of the values
+	upper MinHeap // upper part of the values
+
+	inLower map[uint32]bool // maps the ID of each value to the heap holding it
+}
+
+// add adds a value to the part it belongs to. The caller has to rebalance the
+// parts afterwards.
+func (s *splitHeaps) add(id uint32, value float32) {
+	if s.inLower == nil {
+		s.inLower = map[uint32]bool{}
+	}
+
+	_, lowerMax := s.lower.Top()
+	if s.lower.Len() == 0 || value <= lowerMax {
+		s.lower.Push(id, value)
+		s.inLower[id] = true
+	} else {
+		s.upper.Push(id, value)
+		s.inLower[id] = false
+	}
+}
+
+// remove removes the value with the given ID and returns false if there is no
+// such value. The caller has to rebalance the parts afterwards.
+func (s *splitHeaps) remove(id uint32) bool {
+	lower, ok := s.inLower[id]
+	if !ok {
+		return false
+	}
+
+	delete(s.inLower, id)
+	if lower {
+		s.lower.Cancel(id)
+	} else {
+		s.upper.Cancel(id)
+	}
+
+	return true
+}
+
+// rebalance moves values between the two parts until the lower part contains
+// exactly n values.
+func (s *splitHeaps) rebalance(n int) {
+	for s.lower.Len() > n {
+		item := s.lower.PopItem()
+		s.upper.PushItem(item)
+		s.inLower[item.ID] = false
+	}
+
+	for s.lower.Len() < n && s.upper.Len() > 0 {
+		item := s.upper.PopItem()
+		s.lower.PushItem(item)
+		s.inLower[item.ID] = true
+	}
+}
+
+// len returns the amount of values in both parts.
+func (s *splitHeaps) len() int {
+	return s.lower.Len() + s.upper.Len()
+}
@@ -0,0 +1,98 @@
+package prioqueue_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/stretchr/testify/assert"
+)
+
+func sortedMedian(values []float32) float32 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float32(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return float32((float64(sorted[n/2-1]) + float64(sorted[n/2])) / 2)
+}
+
+func TestRunningMedian(t *testing.T) {
+	var m prioqueue.RunningMedian
+	assert.EqualValues(t, 0, m.Median())
+
+	rng := rand.New(rand.NewSource(42))
+	var values []float32
+	for i := 0; i < 1000; i++ {
+		v := float32(rng.Intn(100)) // produce lots of duplicates
+		values = append(values, v)
+		m.Add(v)
+
+		assert.Equal(t, len(values), m.Len())
+		assert.Equal(t, sortedMedian(values), m.Median(), "after %d values", len(values))
+	}
+}
+
+func TestRunningMedian_SlidingWindow(t *testing.T) {
+	const window = 25
+
+	m := prioqueue.NewRunningMedian()
+	rng := rand.New(rand.NewSource(42))
+
+	var (
+		ids    []uint32
+		values []float32
+	)
+
+	for i := 0; i < 1000; i++ {
+		v := rng.Float32()
+		ids = append(ids, m.Add(v))
+		values = append(values, v)
+
+		if len(ids) > window {
+			m.Remove(ids[0])
+			ids, values = ids[1:], values[1:]
+		}
+
+		assert.Equal(t, len(values), m.Len())
+		assert.Equal(t, sortedMedian(values), m.Median(), "after %d values", i+1)
+	}
+
+	for len(ids) > 0 {
+		m.Remove(ids[len(ids)-1])
+		ids, values = ids[:len(ids)-1], values[:len(values)-1]
+		assert.Equal(t, sortedMedian(values), m.Median())
+	}
+
+	assert.Equal(t, 0, m.Len())
+	m.Remove(12345) // unknown IDs are ignored
+	assert.Equal(t, 0, m.Len())
+}
+
+func ExampleRunningMedian() {
+	m := prioqueue.NewRunningMedian()
+
+	first := m.Add(5)
+	m.Add(1)
+	m.Add(3)
+	fmt.Println(m.Median())
+
+	m.Add(10)
+	fmt.Println(m.Median())
+
+	m.Remove(first)
+	fmt.Println(m.Median())
+
+	// Output:
+	// 3
+	// 4
+	// 3
+}
@@ -0,0 +1,97 @@
+package prioqueue
+
+// Iterator is a cursor over a stream of items.
+type Iterator interface {
+	// Next returns the next item of the stream. The second return value is
+	// false if the stream is exhausted.
+	Next() (Item, bool)
+}
+
+// SliceIterator is an Iterator over the items of a slice.
+type SliceIterator struct {
+	items []Item
+}
+
+// NewSliceIterator returns a new Iterator which yields the given items in
+// order.
+func NewSliceIterator(items []Item) *SliceIterator {
+	return &SliceIterator{items: items}
+}
+
+// Next returns the next item of the slice.
+func (it *SliceIterator) Next() (Item, bool) {
+	if len(it.items) == 0 {
+		return Item{}, false
+	}
+
+	item := it.items[0]
+	it.items = it.items[1:]
+	return item, true
+}
+
+// mergeIterator implements the k-way merge of MergeSorted. It keeps the head
+// of each stream in a MinHeap in which the ID of an item is the index of the
+// stream it belongs to. The MinHeap breaks ties by ID, which makes the merge
+// stable.
+type mergeIterator struct {
+	streams []Iterator
+	heads   []Item // current head of each stream
+	heap    *MinHeap
+}
+
+// MergeSorted merges the given streams into a single stream of items. Each
+// input stream must yield its items in ascending order of their priority. The
+// returned Iterator then yields the items of all streams in ascending order of
+// their priority. The merge is stable, i.e. if multiple streams contain items
+// with the same priority, the items of the stream which was passed first are
+// returned first.
+//
+// MergeSorted reads the first item of each stream immediately. After that, the
+// streams are only advanced when the returned Iterator is advanced. Each
+// item is returned in O(log k) time where k is the amount of streams.
+func MergeSorted(streams ...Iterator) Iterator {
+	m := &mergeIterator{
+		streams: streams,
+		heads:   make([]Item, len(streams)),
+		heap:    NewMinHeap(len(streams)),
+	}
+	m.heap.SetTieBreakByID(true)
+
+	for i, s := range streams {
+		head, ok := s.Next()
+		if !ok {
+			continue
+		}
+
+		m.heads[i] = head
+		m.heap.PushItem(&Item{ID: uint32(i), Prio: head.Prio})
+	}
+
+	return m
+}
+
+// Next returns the item with the lowest priority among the heads of all
+// streams and advances the stream it was taken from.
+func (m *mergeIterator) Next() (Item, bool) {
+	top := m.heap.TopItem()
+	if top == nil {
+		return Item{}, false
+	}
+
+	stream := top.ID
+	item := m.heads[stream]
+
+	next, ok := m.streams[stream].Next()
+	if !ok {
+		m.heap.PopItem()
+		return item, true
+	}
+
+	// Replace the head of the stream with its next item. Since the ID of the
+	// heap item is the index of the stream, ties are broken by stream index.
+	m.heads[stream] = next
+	top.Prio = next.Prio
+	m.heap.PopAndPush(top)
+
+	return item, true
+}
@@ -0,0 +1,85 @@
+package prioqueue_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeSorted(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+
+	var (
+		streams  []prioqueue.Iterator
+		expected []prioqueue.Item
+		id       uint32
+	)
+
+	for i := 0; i < 20; i++ {
+		items := make([]prioqueue.Item, rng.Intn(100))
+		for j := range items {
+			items[j] = prioqueue.Item{ID: id, Prio: float32(rng.Intn(50))}
+			id++
+		}
+
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Prio < items[j].Prio
+		})
+
+		streams = append(streams, prioqueue.NewSliceIterator(items))
+		expected = append(expected, items...)
+	}
+
+	// All items of a stream have higher IDs than the items of the streams
+	// before it, so a stable sort gives the expected order.
+	sort.SliceStable(expected, func(i, j int) bool {
+		return expected[i].Prio < expected[j].Prio
+	})
+
+	var actual []prioqueue.Item
+	it := prioqueue.MergeSorted(streams...)
+	for {
+		item, ok := it.Next()
+		if !ok {
+			break
+		}
+		actual = append(actual, item)
+	}
+
+	assert.Equal(t, expected, actual)
+}
+
+func TestMergeSorted_Empty(t *testing.T) {
+	it := prioqueue.MergeSorted()
+	_, ok := it.Next()
+	assert.False(t, ok)
+
+	it = prioqueue.MergeSorted(prioqueue.NewSliceIterator(nil), prioqueue.NewSliceIterator(nil))
+	_, ok = it.Next()
+	assert.False(t, ok)
+}
+
+func ExampleMergeSorted() {
+	a := prioqueue.NewSliceIterator([]prioqueue.Item{{ID: 1, Prio: 1}, {ID: 2, Prio: 3}, {ID: 3, Prio: 5}})
+	b := prioqueue.NewSliceIterator([]prioqueue.Item{{ID: 4, Prio: 2}, {ID: 5, Prio: 3}})
+
+	it := prioqueue.MergeSorted(a, b)
+	for {
+		item, ok := it.Next()
+		if !ok {
+			break
+		}
+		fmt.Printf("%.0f (id %d)\n", item.Prio, item.ID)
+	}
+
+	// Output:
+	// 1 (id 1)
+	// 2 (id 4)
+	// 3 (id 2)
+	// 3 (id 5)
+	// 5 (id 3)
+}
@@ -0,0 +1,434 @@
+//go:build linux
+// +build linux
+
+package prioqueue
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	mmapMagic   = 0x50514850 // "PQHP"
+	mmapVersion = 1
+
+	// mmapHeaderSize is the size of the header at the start of the file:
+	//
+	//   offset  size  field
+	//   0       4     magic
+	//   4       4     version
+	//   8       8     number of items
+	//   16      8     capacity in items
+	//   24      4     flags
+	//   28      4     CRC-32 (IEEE) of the bytes 0 to 27
+	mmapHeaderSize = 32
+
+	// mmapRecordSize is the size of an item in the file. Each record
+	// contains the ID and the bits of the priority as little endian uint32.
+	mmapRecordSize = 8
+
+	// mmapDefaultCap is the initial capacity of a new file if no capacity is
+	// given to OpenMmapHeap.
+	mmapDefaultCap = 1024
+
+	// mmapFlagDirty is set in the header while the heap has been modified
+	// since it was last synced. The number of items in a dirty header is
+	// updated on every modification but its checksum is not.
+	mmapFlagDirty = 1
+)
+
+var (
+	// ErrInvalidHeader is returned by OpenMmapHeap if the file is not a heap
+	// file or its header is corrupt.
+	ErrInvalidHeader = errors.New("prioqueue: invalid heap file header")
+
+	// ErrUnclean is returned by OpenMmapHeap if the heap in the file was
+	// modified but not synced or closed afterwards, for instance because the
+	// process crashed. The items in such a file may not form a valid heap, but
+	// the file can be opened with RecoverMmapHeap.
+	ErrUnclean = errors.New("prioqueue: heap file was not closed cleanly, use RecoverMmapHeap")
+)
+
+// MmapHeap implements a priority queue which allows to retrieve the highest
+// priority element, just like the MaxHeap. Instead of a slice of pointers, the
+// binary heap is stored as fixed-size records in a memory mapped file. This
+// does not put any pressure on the garbage collector, even for hundreds of
+// millions of items, and the queue can be reopened instantly after the
+// process has been restarted.
+//
+// The file starts with a header which contains the number of items and the
+// capacity of the file as well as a checksum. The checksum is only updated when
+// the heap is synced or closed. A heap which was modified but not synced cannot
+// be opened again by OpenMmapHeap since its items may be in an inconsistent
+// state. RecoverMmapHeap restores the heap order of such a file instead. The
+// file grows automatically if more items are pushed than it has capacity for.
+//
+// Items with equal priority are dequeued in ascending order of their IDs.
+//
+// Time Complexity
+//
+//   Push and Pop take O(log n) and Top() happens in constant time. Push takes
+//   O(n) if the file needs to grow.
+type MmapHeap struct {
+	file  *os.File
+	data  []byte // the mapped file
+	len   int
+	cap   int
+	dirty bool
+}
+
+// OpenMmapHeap opens the heap file at the given path. If the file does not
+// exist or is empty, a new heap with the given capacity is created. Otherwise
+// the capacity argument is ignored.
+func OpenMmapHeap(path string, capacity int) (*MmapHeap, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := openMmapHeap(f, capacity, false)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// RecoverMmapHeap opens the heap file at the given path like OpenMmapHeap but
+// also accepts a file which was not closed cleanly. The items which were in
+// the heap at the time of the crash are reordered into a valid heap, and the
+// recovered heap is synced before it is returned.
+//
+// If the process crashed, all items are recovered. If the operating system
+// crashed, the items of the last modifications before the crash may be lost
+// or restored.
+func RecoverMmapHeap(path string) (*MmapHeap, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := openMmapHeap(f, 0, true)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return h, nil
+}
+
+func openMmapHeap(f *os.File, capacity int, recover bool) (*MmapHeap, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	h := &MmapHeap{file: f}
+	if info.Size() == 0 {
+		if capacity <= 0 {
+			capacity = mmapDefaultCap
+		}
+		if err := h.resize(capacity); err != nil {
+			return nil, err
+		}
+		h.writeHeader()
+		return h, nil
+	}
+
+	if info.Size() < mmapHeaderSize {
+		return nil, ErrInvalidHeader
+	}
+
+	h.data, err = syscall.Mmap(int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("prioqueue: failed to map heap file: %w", err)
+	}
+
+	err = h.readHeader(info.Size(), recover)
+	if err == nil && h.dirty {
+		// Only a recovered file is still dirty.
+		h.heapify()
+		err = h.Sync()
+	}
+	if err != nil {
+		_ = syscall.Munmap(h.data)
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// Len returns the amount of elements in the queue.
+func (h *MmapHeap) Len() int {
+	return h.len
+}
+
+// Cap returns the amount of elements which fit into the file without growing
+// it.
+func (h *MmapHeap) Cap() int {
+	return h.cap
+}
+
+// Top returns the ID and priority of the item with the highest priority value
+// in the queue without removing it.
+func (h *MmapHeap) Top() (id uint32, prio float32) {
+	if h.len == 0 {
+		return 0, 0
+	}
+	return h.item(0)
+}
+
+// Push the value item into the priority queue with provided priority. If the
+// file is full, its capacity is doubled. Push only returns an error if growing
+// the file failed.
+func (h *MmapHeap) Push(id uint32, prio float32) error {
+	if h.len == h.cap {
+		capacity := 2 * h.cap
+		if capacity == 0 {
+			capacity = mmapDefaultCap
+		}
+		if err := h.resize(capacity); err != nil {
+			return err
+		}
+	}
+
+	h.modify()
+
+	// Move parents down until the position of the new item is found.
+	i := h.len
+	h.setLen(h.len + 1)
+	for i > 0 {
+		parent := (i - 1) / 2
+		pid, pprio := h.item(parent)
+		if !(prio > pprio || (prio == pprio && id < pid)) {
+			break
+		}
+
+		h.setItem(i, pid, pprio)
+		i = parent
+	}
+
+	h.setItem(i, id, prio)
+	return nil
+}
+
+// Pop removes the item with the highest priority value from the queue and
+// returns its ID and priority.
+func (h *MmapHeap) Pop() (id uint32, prio float32) {
+	if h.len == 0 {
+		return 0, 0
+	}
+
+	h.modify()
+	id, prio = h.item(0)
+	h.setLen(h.len - 1)
+	if h.len > 0 {
+		lid, lprio := h.item(h.len)
+		h.shiftDown(0, lid, lprio)
+	}
+
+	return id, prio
+}
+
+// Reset empties the queue. The size of the file does not change.
+func (h *MmapHeap) Reset() {
+	h.modify()
+	h.setLen(0)
+}
+
+// Sync writes the header and all items to disk. The items are flushed before
+// the header marks the file as clean, so a crash during Sync leaves a file
+// which can be recovered with RecoverMmapHeap.
+func (h *MmapHeap) Sync() error {
+	if !h.dirty {
+		return h.flush()
+	}
+
+	h.writeHeader()
+	if err := h.flush(); err != nil {
+		return err
+	}
+
+	h.dirty = false
+	h.writeHeader()
+	return h.flush()
+}
+
+// Close syncs the heap to disk and closes the file. The heap must not be used
+// anymore afterwards.
+func (h *MmapHeap) Close() error {
+	err := h.Sync()
+	if uerr := syscall.Munmap(h.data); uerr != nil && err == nil {
+		err = uerr
+	}
+	h.data = nil
+
+	if cerr := h.file.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// shiftDown places the given item, which replaces the item at index i, by
+// moving the larger child up until the heap property is satisfied.
+func (h *MmapHeap) shiftDown(i int, id uint32, prio float32) {
+	for {
+		j := 2*i + 1
+		if j >= h.len {
+			break
+		}
+
+		cid, cprio := h.item(j)
+		if j+1 < h.len {
+			rid, rprio := h.item(j + 1)
+			if rprio > cprio || (rprio == cprio && rid < cid) {
+				j, cid, cprio = j+1, rid, rprio
+			}
+		}
+
+		if !(cprio > prio || (cprio == prio && cid < id)) {
+			break
+		}
+
+		h.setItem(i, cid, cprio)
+		i = j
+	}
+
+	h.setItem(i, id, prio)
+}
+
+// heapify restores the heap property of all items in O(n).
+func (h *MmapHeap) heapify() {
+	for i := h.len/2 - 1; i >= 0; i-- {
+		id, prio := h.item(i)
+		h.shiftDown(i, id, prio)
+	}
+}
+
+func (h *MmapHeap) item(i int) (id uint32, prio float32) {
+	b := h.data[mmapHeaderSize+i*mmapRecordSize:]
+	return binary.LittleEndian.Uint32(b), math.Float32frombits(binary.LittleEndian.Uint32(b[4:]))
+}
+
+func (h *MmapHeap) setItem(i int, id uint32, prio float32) {
+	b := h.data[mmapHeaderSize+i*mmapRecordSize:]
+	binary.LittleEndian.PutUint32(b, id)
+	binary.LittleEndian.PutUint32(b[4:], math.Float32bits(prio))
+}
+
+// modify marks the file as dirty before it is modified for the first time
+// after it was opened or synced.
+func (h *MmapHeap) modify() {
+	if !h.dirty {
+		h.dirty = true
+		h.writeHeader()
+	}
+}
+
+// setLen changes the number of items and updates it in the dirty header so the
+// items can be recovered after a crash.
+func (h *MmapHeap) setLen(n int) {
+	h.len = n
+	binary.LittleEndian.PutUint64(h.data[8:], uint64(n))
+}
+
+// flush writes the mapped file to disk.
+func (h *MmapHeap) flush() error {
+	_, _, errno := syscall.Syscall(syscall.SYS_MSYNC, uintptr(unsafe.Pointer(&h.data[0])), uintptr(len(h.data)), syscall.MS_SYNC)
+	if errno != 0 {
+		return fmt.Errorf("prioqueue: failed to flush heap file: %w", errno)
+	}
+	return h.file.Sync()
+}
+
+// resize grows the file to the given capacity and maps it again. The old
+// mapping is only replaced once the file was grown and mapped successfully, so
+// the heap stays usable if resize fails.
+func (h *MmapHeap) resize(capacity int) error {
+	size := mmapHeaderSize + capacity*mmapRecordSize
+	if err := h.file.Truncate(int64(size)); err != nil {
+		return err
+	}
+
+	data, err := syscall.Mmap(int(h.file.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		if h.data != nil {
+			// Restore the old size so the file still matches its header.
+			_ = h.file.Truncate(int64(len(h.data)))
+		}
+		return fmt.Errorf("prioqueue: failed to map heap file: %w", err)
+	}
+
+	old := h.data
+	h.data = data
+	h.cap = capacity
+	h.writeHeader()
+
+	if old != nil {
+		return syscall.Munmap(old)
+	}
+	return nil
+}
+
+func (h *MmapHeap) writeHeader() {
+	var flags uint32
+	if h.dirty {
+		flags |= mmapFlagDirty
+	}
+
+	b := h.data[:mmapHeaderSize]
+	binary.LittleEndian.PutUint32(b[0:], mmapMagic)
+	binary.LittleEndian.PutUint32(b[4:], mmapVersion)
+	binary.LittleEndian.PutUint64(b[8:], uint64(h.len))
+	binary.LittleEndian.PutUint64(b[16:], uint64(h.cap))
+	binary.LittleEndian.PutUint32(b[24:], flags)
+	binary.LittleEndian.PutUint32(b[28:], crc32.ChecksumIEEE(b[:28]))
+}
+
+// readHeader validates the header of a file with the given size and restores
+// the length and capacity of the heap from it. A dirty header is only accepted
+// if the heap is recovered. Its checksum is not verified since the number of
+// items changed after it was written. When recovering, the file may also be
+// larger than the header says since the process may have crashed while the
+// file was grown. Such a file is marked dirty so its header is rewritten.
+func (h *MmapHeap) readHeader(size int64, recover bool) error {
+	b := h.data[:mmapHeaderSize]
+	if binary.LittleEndian.Uint32(b[0:]) != mmapMagic ||
+		binary.LittleEndian.Uint32(b[4:]) != mmapVersion {
+		return ErrInvalidHeader
+	}
+
+	dirty := binary.LittleEndian.Uint32(b[24:])&mmapFlagDirty != 0
+	if dirty && !recover {
+		return ErrUnclean
+	}
+	if !dirty && binary.LittleEndian.Uint32(b[28:]) != crc32.ChecksumIEEE(b[:28]) {
+		return ErrInvalidHeader
+	}
+
+	n := binary.LittleEndian.Uint64(b[8:])
+	capacity := binary.LittleEndian.Uint64(b[16:])
+	records := uint64(size - mmapHeaderSize)
+	if n > capacity || records%mmapRecordSize != 0 {
+		return ErrInvalidHeader
+	}
+	if records /= mmapRecordSize; records != capacity {
+		if !recover || records < capacity {
+			return ErrInvalidHeader
+		}
+		capacity = records
+		dirty = true
+	}
+
+	h.len = int(n)
+	h.cap = int(capacity)
+	h.dirty = dirty
+	return nil
+}
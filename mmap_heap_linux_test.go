@@ -0,0 +1,310 @@
+package prioqueue_test
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMmapHeap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heap")
+	h, err := prioqueue.OpenMmapHeap(path, 1)
+	require.NoError(t, err)
+	defer h.Close()
+
+	id, prio := h.Pop()
+	assert.EqualValues(t, 0, id)
+	assert.EqualValues(t, 0, prio)
+
+	model := prioqueue.NewMaxHeap(0)
+	model.SetTieBreakByID(true)
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < 10_000; i++ {
+		if rng.Intn(3) > 0 {
+			prio := float32(rng.Intn(100))
+			require.NoError(t, h.Push(uint32(i), prio))
+			model.Push(uint32(i), prio)
+			continue
+		}
+
+		topID, topPrio := h.Top()
+		id, prio := h.Pop()
+		expectedID, expectedPrio := model.Pop()
+		require.Equal(t, topID, id)
+		require.Equal(t, topPrio, prio)
+		require.Equal(t, expectedID, id)
+		require.Equal(t, expectedPrio, prio)
+		require.Equal(t, model.Len(), h.Len())
+	}
+
+	assert.GreaterOrEqual(t, h.Cap(), h.Len())
+
+	h.Reset()
+	assert.Equal(t, 0, h.Len())
+}
+
+func TestMmapHeap_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heap")
+	h, err := prioqueue.OpenMmapHeap(path, 4)
+	require.NoError(t, err)
+
+	for i := uint32(0); i < 100; i++ {
+		require.NoError(t, h.Push(i, float32(i%10)))
+	}
+	h.Pop()
+	require.NoError(t, h.Close())
+
+	h, err = prioqueue.OpenMmapHeap(path, 0)
+	require.NoError(t, err)
+	defer h.Close()
+
+	assert.Equal(t, 99, h.Len())
+	assert.Equal(t, 128, h.Cap())
+
+	id, prio := h.Pop()
+	assert.EqualValues(t, 19, id)
+	assert.EqualValues(t, 9, prio)
+}
+
+func TestMmapHeap_Unclean(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heap")
+	h, err := prioqueue.OpenMmapHeap(path, 0)
+	require.NoError(t, err)
+	defer h.Close()
+
+	// Opening the file while it is in use simulates a crash.
+	require.NoError(t, h.Push(1, 1))
+	_, err = prioqueue.OpenMmapHeap(path, 0)
+	assert.Equal(t, prioqueue.ErrUnclean, err)
+
+	require.NoError(t, h.Sync())
+	other, err := prioqueue.OpenMmapHeap(path, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, other.Len())
+	require.NoError(t, other.Close())
+}
+
+func TestMmapHeap_Recover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heap")
+	h, err := prioqueue.OpenMmapHeap(path, 0)
+	require.NoError(t, err)
+	defer h.Close()
+
+	model := prioqueue.NewMaxHeap(0)
+	model.SetTieBreakByID(true)
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < 5000; i++ {
+		if i == 1000 {
+			require.NoError(t, h.Sync())
+		}
+		if rng.Intn(3) > 0 {
+			prio := float32(rng.Intn(100))
+			require.NoError(t, h.Push(uint32(i), prio))
+			model.Push(uint32(i), prio)
+			continue
+		}
+		h.Pop()
+		model.Pop()
+	}
+
+	// Opening the file while it is in use simulates a crash. The root and the
+	// last item are swapped to check that the heap order is restored.
+	_, err = prioqueue.OpenMmapHeap(path, 0)
+	require.Equal(t, prioqueue.ErrUnclean, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	last := 32 + 8*(h.Len()-1)
+	records := append(append([]byte(nil), data[last:last+8]...), data[32:40]...)
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteAt(records[:8], 32)
+	require.NoError(t, err)
+	_, err = f.WriteAt(records[8:], int64(last))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	recovered, err := prioqueue.RecoverMmapHeap(path)
+	require.NoError(t, err)
+	assert.Equal(t, model.Len(), recovered.Len())
+	require.NoError(t, recovered.Close())
+
+	// The recovered file is clean again.
+	recovered, err = prioqueue.OpenMmapHeap(path, 0)
+	require.NoError(t, err)
+	defer recovered.Close()
+
+	for model.Len() > 0 {
+		id, prio := recovered.Pop()
+		expectedID, expectedPrio := model.Pop()
+		require.Equal(t, expectedID, id)
+		require.Equal(t, expectedPrio, prio)
+	}
+	assert.Equal(t, 0, recovered.Len())
+}
+
+func TestMmapHeap_RecoverClean(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heap")
+	h, err := prioqueue.OpenMmapHeap(path, 0)
+	require.NoError(t, err)
+	require.NoError(t, h.Push(1, 1))
+	require.NoError(t, h.Push(2, 2))
+	require.NoError(t, h.Close())
+
+	h, err = prioqueue.RecoverMmapHeap(path)
+	require.NoError(t, err)
+	defer h.Close()
+
+	assert.Equal(t, 2, h.Len())
+	id, prio := h.Pop()
+	assert.EqualValues(t, 2, id)
+	assert.EqualValues(t, 2, prio)
+
+	_, err = prioqueue.RecoverMmapHeap(filepath.Join(t.TempDir(), "missing"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMmapHeap_GrowError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heap")
+	h, err := prioqueue.OpenMmapHeap(path, 4)
+	require.NoError(t, err)
+	for i := uint32(0); i < 4; i++ {
+		require.NoError(t, h.Push(i, float32(i)))
+	}
+
+	// Limiting the file size makes growing the file fail. The Go runtime
+	// ignores the SIGXFSZ signal, so the error is returned instead.
+	var limit syscall.Rlimit
+	require.NoError(t, syscall.Getrlimit(syscall.RLIMIT_FSIZE, &limit))
+	small := limit
+	small.Cur = 64
+	require.NoError(t, syscall.Setrlimit(syscall.RLIMIT_FSIZE, &small))
+	err = h.Push(4, 4)
+	require.NoError(t, syscall.Setrlimit(syscall.RLIMIT_FSIZE, &limit))
+	require.Error(t, err)
+
+	// The heap is still usable and can be closed.
+	assert.Equal(t, 4, h.Len())
+	id, prio := h.Pop()
+	assert.EqualValues(t, 3, id)
+	assert.EqualValues(t, 3, prio)
+	require.NoError(t, h.Push(5, 5))
+	require.NoError(t, h.Close())
+
+	h, err = prioqueue.OpenMmapHeap(path, 0)
+	require.NoError(t, err)
+	defer h.Close()
+	assert.Equal(t, 4, h.Len())
+	assert.Equal(t, 4, h.Cap())
+}
+
+func TestMmapHeap_RecoverGrown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heap")
+	h, err := prioqueue.OpenMmapHeap(path, 4)
+	require.NoError(t, err)
+	for i := uint32(0); i < 3; i++ {
+		require.NoError(t, h.Push(i, float32(i)))
+	}
+	require.NoError(t, h.Close())
+
+	// A crash while the file grows leaves a file which is larger than its
+	// header says.
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()+4*8))
+	_, err = prioqueue.OpenMmapHeap(path, 0)
+	assert.Equal(t, prioqueue.ErrInvalidHeader, err)
+
+	h, err = prioqueue.RecoverMmapHeap(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, h.Len())
+	assert.Equal(t, 8, h.Cap())
+	require.NoError(t, h.Close())
+
+	h, err = prioqueue.OpenMmapHeap(path, 0)
+	require.NoError(t, err)
+	defer h.Close()
+	assert.Equal(t, 3, h.Len())
+	assert.Equal(t, 8, h.Cap())
+	id, prio := h.Pop()
+	assert.EqualValues(t, 2, id)
+	assert.EqualValues(t, 2, prio)
+}
+
+func TestMmapHeap_InvalidHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "heap")
+	h, err := prioqueue.OpenMmapHeap(path, 0)
+	require.NoError(t, err)
+	require.NoError(t, h.Push(1, 1))
+	require.NoError(t, h.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	corrupt := append([]byte(nil), data...)
+	corrupt[8]++ // the number of items
+	require.NoError(t, os.WriteFile(path, corrupt, 0o644))
+	_, err = prioqueue.OpenMmapHeap(path, 0)
+	assert.Equal(t, prioqueue.ErrInvalidHeader, err)
+
+	require.NoError(t, os.WriteFile(path, data[:len(data)-8], 0o644))
+	_, err = prioqueue.OpenMmapHeap(path, 0)
+	assert.Equal(t, prioqueue.ErrInvalidHeader, err, "the file is truncated")
+
+	require.NoError(t, os.WriteFile(path, []byte("not a heap"), 0o644))
+	_, err = prioqueue.OpenMmapHeap(path, 0)
+	assert.Equal(t, prioqueue.ErrInvalidHeader, err)
+}
+
+// BenchmarkMmapHeap_Push200 tests how fast we can push 200 elements on the
+// MmapHeap.
+func BenchmarkMmapHeap_Push200(b *testing.B) {
+	h, err := prioqueue.OpenMmapHeap(filepath.Join(b.TempDir(), "heap"), 200)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer h.Close()
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		for id := uint32(0); id < 200; id++ {
+			_ = h.Push(id, randValues[id])
+		}
+
+		b.StopTimer()
+		h.Reset()
+		b.StartTimer()
+	}
+}
+
+// BenchmarkMmapHeap_Pop200 tests how long it takes to pop all elements from an
+// MmapHeap which contains 200 random elements.
+func BenchmarkMmapHeap_Pop200(b *testing.B) {
+	h, err := prioqueue.OpenMmapHeap(filepath.Join(b.TempDir(), "heap"), 200)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer h.Close()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		for id := uint32(0); id < 200; id++ {
+			_ = h.Push(id, randValues[id])
+		}
+		b.StartTimer()
+
+		for h.Len() > 0 {
+			h.Pop()
+		}
+	}
+}
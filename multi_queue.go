@@ -0,0 +1,197 @@
+package prioqueue
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// MultiQueue is a concurrency-safe priority queue which is designed for high
+// contention. Instead of protecting a single heap with a single lock, it
+// distributes the items over several MaxHeap shards which each have their own
+// lock. Push adds an item to a random shard. Pop looks at the top items of two
+// random shards and removes the item with the higher priority.
+//
+// Relaxed ordering
+//
+// In contrast to the MaxHeap, a MultiQueue does not guarantee that Pop returns
+// the item with the highest priority. It only returns an item with one of the
+// highest priorities. On average, the rank of the returned item (i.e. the
+// amount of items in the queue with a higher priority) is in the order of the
+// amount of shards, and items with a higher rank are exponentially unlikely.
+// Using a single shard makes the MultiQueue exact but then it has no advantage
+// over a MaxHeap with a mutex.
+//
+// Similarly, Len returns only an approximation while other goroutines are
+// modifying the queue, and Pop may return nil if other goroutines push items
+// into the queue concurrently.
+//
+// This design is known as MultiQueue, see "MultiQueues: Simpler, Faster, and
+// Better Relaxed Concurrent Priority Queues" by Rihani, Sanders and Dementiev.
+type MultiQueue struct {
+	seed   uint64 // accessed atomically, so it must be 64-bit aligned
+	shards []multiQueueShard
+	rngs   sync.Pool // *uint64 states of xorshift random number generators
+}
+
+// multiQueueShard is a MaxHeap with its own lock. Each shard is padded to a
+// multiple of 64 bytes to avoid false sharing between shards. This also keeps
+// the length of every shard in a slice 64-bit aligned, which is required for
+// atomic operations on 32-bit platforms.
+type multiQueueShard struct {
+	multiQueueShardState
+	_ [64 - unsafe.Sizeof(multiQueueShardState{})%64]byte
+}
+
+// multiQueueShardState contains the fields of a multiQueueShard. The length
+// and the priority of the top item are cached so Pop can compare shards
+// without locking them.
+type multiQueueShardState struct {
+	len int64  // accessed atomically; must be the first field
+	top uint32 // bits of the top priority; accessed atomically
+
+	mu   sync.Mutex
+	heap MaxHeap
+}
+
+// NewMultiQueue returns a new MultiQueue with the given amount of shards. If
+// shards is less than 1, twice the amount of usable CPUs is used which is a
+// good default for most workloads.
+func NewMultiQueue(shards int) *MultiQueue {
+	if shards < 1 {
+		shards = 2 * runtime.GOMAXPROCS(0)
+	}
+
+	q := &MultiQueue{shards: make([]multiQueueShard, shards)}
+	q.rngs.New = func() interface{} {
+		// Use different seeds for each generator. The seed must not be 0.
+		state := splitMix64(atomic.AddUint64(&q.seed, 1))
+		return &state
+	}
+
+	return q
+}
+
+// Len returns the amount of elements in the queue.
+func (q *MultiQueue) Len() int {
+	var n int64
+	for i := range q.shards {
+		n += atomic.LoadInt64(&q.shards[i].len)
+	}
+	return int(n)
+}
+
+// Push the value item into the priority queue with provided priority.
+func (q *MultiQueue) Push(id uint32, prio float32) {
+	q.PushItem(&Item{ID: id, Prio: prio})
+}
+
+// PushItem adds an Item to a random shard of the queue.
+func (q *MultiQueue) PushItem(item *Item) {
+	s := &q.shards[q.random(len(q.shards))]
+	s.mu.Lock()
+	s.heap.PushItem(item)
+	s.updateCache()
+	s.mu.Unlock()
+}
+
+// Pop removes an item with one of the highest priority values from the queue
+// and returns its ID and priority.
+func (q *MultiQueue) Pop() (id uint32, priority float32) {
+	i := q.PopItem()
+	if i == nil {
+		return 0, 0
+	}
+
+	return i.ID, i.Prio
+}
+
+// PopItem removes an item with one of the highest priority values from the
+// queue. It returns nil if the queue is empty.
+func (q *MultiQueue) PopItem() *Item {
+	n := len(q.shards)
+
+	// Try a few times to pop from the better of two random shards.
+	for attempt := 0; attempt < 4; attempt++ {
+		a := &q.shards[q.random(n)]
+		b := &q.shards[q.random(n)]
+
+		aLen, bLen := atomic.LoadInt64(&a.len), atomic.LoadInt64(&b.len)
+		switch {
+		case aLen == 0 && bLen == 0:
+			continue
+		case aLen == 0:
+			a = b
+		case bLen > 0 && b.cachedTop() > a.cachedTop():
+			a = b
+		}
+
+		if item := a.pop(); item != nil {
+			return item
+		}
+	}
+
+	// The queue seems to be (almost) empty, so check all shards before
+	// giving up.
+	start := q.random(n)
+	for i := 0; i < n; i++ {
+		if item := q.shards[(start+i)%n].pop(); item != nil {
+			return item
+		}
+	}
+
+	return nil
+}
+
+// random returns a random number in [0, n).
+func (q *MultiQueue) random(n int) int {
+	state := q.rngs.Get().(*uint64)
+
+	// xorshift64
+	x := *state
+	x ^= x << 13
+	x ^= x >> 7
+	x ^= x << 17
+	*state = x
+
+	q.rngs.Put(state)
+	return int(x % uint64(n))
+}
+
+// pop removes the item with the highest priority from the shard.
+func (s *multiQueueShard) pop() *Item {
+	s.mu.Lock()
+	item := s.heap.PopItem()
+	s.updateCache()
+	s.mu.Unlock()
+	return item
+}
+
+// updateCache stores the length and top priority of the heap. The caller must
+// hold the lock of the shard.
+func (s *multiQueueShard) updateCache() {
+	if top := s.heap.TopItem(); top != nil {
+		atomic.StoreUint32(&s.top, math.Float32bits(top.Prio))
+	}
+	atomic.StoreInt64(&s.len, int64(s.heap.Len()))
+}
+
+// cachedTop returns the priority of the top item of the shard when it was
+// last modified.
+func (s *multiQueueShard) cachedTop() float32 {
+	return math.Float32frombits(atomic.LoadUint32(&s.top))
+}
+
+// splitMix64 returns a well-mixed, non-zero 64 bit value for the given input.
+func splitMix64(x uint64) uint64 {
+	x += 0x9E3779B97F4A7C15
+	x = (x ^ (x >> 30)) * 0xBF58476D1CE4E5B9
+	x = (x ^ (x >> 27)) * 0x94D049BB133111EB
+	x ^= x >> 31
+	if x == 0 {
+		x = 1
+	}
+	return x
+}
@@ -0,0 +1,119 @@
+package prioqueue_test
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiQueue_SingleShard(t *testing.T) {
+	q := prioqueue.NewMultiQueue(1)
+
+	id, prio := q.Pop()
+	assert.EqualValues(t, 0, id)
+	assert.EqualValues(t, 0, prio)
+
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < 1000; i++ {
+		q.Push(uint32(i), rng.Float32())
+	}
+	require.Equal(t, 1000, q.Len())
+
+	var last float32 = 2
+	for q.Len() > 0 {
+		_, prio := q.Pop()
+		assert.LessOrEqual(t, prio, last, "a single shard should be exact")
+		last = prio
+	}
+	assert.Nil(t, q.PopItem())
+}
+
+func TestMultiQueue_RankError(t *testing.T) {
+	const (
+		n      = 10_000
+		shards = 8
+	)
+
+	q := prioqueue.NewMultiQueue(shards)
+	rng := rand.New(rand.NewSource(42))
+	prios := make([]float32, n)
+	for i := range prios {
+		prios[i] = rng.Float32()
+		q.Push(uint32(i), prios[i])
+	}
+
+	// remaining contains all priorities that are still in the queue in
+	// descending order, so the rank of a popped item is its index.
+	remaining := append([]float32(nil), prios...)
+	sort.Slice(remaining, func(i, j int) bool { return remaining[i] > remaining[j] })
+
+	var totalRank, maxRank int
+	for q.Len() > 0 {
+		_, prio := q.Pop()
+		rank := sort.Search(len(remaining), func(i int) bool { return remaining[i] <= prio })
+		require.Equal(t, prio, remaining[rank])
+		remaining = append(remaining[:rank], remaining[rank+1:]...)
+
+		totalRank += rank
+		if rank > maxRank {
+			maxRank = rank
+		}
+	}
+
+	assert.Empty(t, remaining)
+	t.Logf("mean rank error %.2f, max rank error %d", float64(totalRank)/n, maxRank)
+	assert.Less(t, float64(totalRank)/n, float64(shards), "mean rank error should be in the order of the amount of shards")
+}
+
+func TestMultiQueue_Concurrent(t *testing.T) {
+	const (
+		goroutines = 8
+		perRoutine = 10_000
+	)
+
+	q := prioqueue.NewMultiQueue(0)
+
+	var wg sync.WaitGroup
+	popped := make([][]uint32, goroutines)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(int64(g)))
+			for i := 0; i < perRoutine; i++ {
+				q.Push(uint32(g*perRoutine+i), rng.Float32())
+				if i%2 == 1 {
+					if item := q.PopItem(); item != nil {
+						popped[g] = append(popped[g], item.ID)
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	seen := make([]bool, goroutines*perRoutine)
+	count := 0
+	mark := func(id uint32) {
+		require.False(t, seen[id], "item %d was popped twice", id)
+		seen[id] = true
+		count++
+	}
+
+	for _, ids := range popped {
+		for _, id := range ids {
+			mark(id)
+		}
+	}
+	for q.Len() > 0 {
+		mark(q.PopItem().ID)
+	}
+
+	assert.Equal(t, goroutines*perRoutine, count)
+	assert.Nil(t, q.PopItem())
+}
@@ -0,0 +1,121 @@
+package prioqueue
+
+// Observer is notified about the operations on a MaxHeap or MinHeap, for
+// instance to export metrics about the queue. An Observer is set via the
+// SetObserver method of the heap. All methods are called synchronously after
+// the heap has been modified, so they should return quickly and must not
+// modify the heap.
+type Observer interface {
+	// OnPush is called after an item was added to the heap. Size is the new
+	// length of the heap and capacity the capacity of its backing array.
+	OnPush(item *Item, size, capacity int)
+
+	// OnPop is called after an item was removed from the heap. Items which
+	// have been cancelled are not reported when they are dropped.
+	OnPop(item *Item, size, capacity int)
+
+	// OnShrink is called after the backing array of the heap was shrunk,
+	// either by Shrink or automatically (see SetAutoShrink). Size is the
+	// length of the heap and capacity the new capacity of its backing array.
+	OnShrink(size, capacity int)
+
+	// OnReset is called after the heap was reset.
+	OnReset()
+
+	// OnHighWaterMark is called when a push makes the length of the heap
+	// reach the high-water mark. It is not called again until the length has
+	// dropped below the mark, either by a pop or by cancelling items.
+	OnHighWaterMark(size int)
+}
+
+// Counters is an Observer which counts the operations on a heap. Counters must
+// not be read while the heap is in use by another goroutine.
+type Counters struct {
+	Pushes         uint64
+	Pops           uint64
+	Resets         uint64
+	HighWaterMarks uint64
+
+	MaxLen int // the largest length the heap had since it was observed
+	Cap    int // the current capacity of the backing array of the heap
+}
+
+// OnPush implements the Observer interface.
+func (c *Counters) OnPush(_ *Item, size, capacity int) {
+	c.Pushes++
+	c.Cap = capacity
+	if size > c.MaxLen {
+		c.MaxLen = size
+	}
+}
+
+// OnPop implements the Observer interface.
+func (c *Counters) OnPop(_ *Item, _, capacity int) {
+	c.Pops++
+	c.Cap = capacity
+}
+
+// OnShrink implements the Observer interface.
+func (c *Counters) OnShrink(_, capacity int) {
+	c.Cap = capacity
+}
+
+// OnReset implements the Observer interface.
+func (c *Counters) OnReset() {
+	c.Resets++
+}
+
+// OnHighWaterMark implements the Observer interface.
+func (c *Counters) OnHighWaterMark(int) {
+	c.HighWaterMarks++
+}
+
+// observation holds the Observer of a heap and tracks whether the heap is
+// above its high-water mark.
+type observation struct {
+	observer      Observer
+	highWaterMark int
+	aboveMark     bool
+}
+
+func (o *observation) set(observer Observer, highWaterMark int, size int) {
+	o.observer = observer
+	o.highWaterMark = highWaterMark
+	o.aboveMark = highWaterMark > 0 && size >= highWaterMark
+}
+
+func (o *observation) pushed(item *Item, size, capacity int) {
+	o.observer.OnPush(item, size, capacity)
+	if o.highWaterMark > 0 && size >= o.highWaterMark && !o.aboveMark {
+		o.aboveMark = true
+		o.observer.OnHighWaterMark(size)
+	}
+}
+
+func (o *observation) popped(item *Item, size, capacity int) {
+	o.observer.OnPop(item, size, capacity)
+	if size < o.highWaterMark {
+		o.aboveMark = false
+	}
+}
+
+// cancelled updates the high-water mark after items were cancelled. Cancelled
+// items are not reported to the Observer.
+func (o *observation) cancelled(size int) {
+	if size < o.highWaterMark {
+		o.aboveMark = false
+	}
+}
+
+// replaced notifies the Observer about a pop which was immediately followed by
+// a push. The length of the heap did not change, so the high-water mark is not
+// affected.
+func (o *observation) replaced(old, item *Item, size, capacity int) {
+	o.observer.OnPop(old, size-1, capacity)
+	o.observer.OnPush(item, size, capacity)
+}
+
+func (o *observation) reset() {
+	o.aboveMark = false
+	o.observer.OnReset()
+}
@@ -0,0 +1,202 @@
+package prioqueue_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder is an Observer which records all notifications.
+type recorder struct {
+	events []string
+}
+
+func (r *recorder) OnPush(item *prioqueue.Item, size, capacity int) {
+	r.events = append(r.events, fmt.Sprintf("push %d size=%d", item.ID, size))
+}
+
+func (r *recorder) OnPop(item *prioqueue.Item, size, capacity int) {
+	r.events = append(r.events, fmt.Sprintf("pop %d size=%d", item.ID, size))
+}
+
+func (r *recorder) OnShrink(size, capacity int) {
+	r.events = append(r.events, fmt.Sprintf("shrink size=%d cap=%d", size, capacity))
+}
+
+func (r *recorder) OnReset() {
+	r.events = append(r.events, "reset")
+}
+
+func (r *recorder) OnHighWaterMark(size int) {
+	r.events = append(r.events, fmt.Sprintf("high-water mark size=%d", size))
+}
+
+// observedQueue is implemented by the MaxHeap and MinHeap.
+type observedQueue interface {
+	Push(id uint32, prio float32)
+	Pop() (uint32, float32)
+	PopAndPush(*prioqueue.Item)
+	Cancel(id uint32)
+	Reset()
+	SetObserver(prioqueue.Observer, int)
+	SetTieBreakByID(bool)
+}
+
+func TestObserver(t *testing.T) {
+	for _, q := range []observedQueue{prioqueue.NewMaxHeap(0), prioqueue.NewMinHeap(0)} {
+		t.Run(fmt.Sprintf("%T", q), func(t *testing.T) {
+			r := new(recorder)
+			q.SetObserver(r, 2)
+			q.SetTieBreakByID(true)
+
+			q.Push(1, 1)
+			q.Push(2, 1)
+			q.Push(3, 1)
+			q.Pop()
+			q.Pop()
+			q.Push(4, 1) // reaches the high-water mark again
+			q.PopAndPush(&prioqueue.Item{ID: 5, Prio: 1})
+			q.Cancel(5) // dropping a cancelled item is not a pop
+			q.Pop()
+			q.Reset()
+			q.Pop() // popping an empty queue is not reported
+
+			q.SetObserver(nil, 0)
+			q.Push(6, 1)
+
+			assert.Equal(t, []string{
+				"push 1 size=1",
+				"push 2 size=2",
+				"high-water mark size=2",
+				"push 3 size=3",
+				"pop 1 size=2",
+				"pop 2 size=1",
+				"push 4 size=2",
+				"high-water mark size=2",
+				"pop 3 size=1",
+				"push 5 size=2",
+				"pop 4 size=0",
+				"reset",
+			}, r.events)
+		})
+	}
+}
+
+func TestObserver_SetAboveHighWaterMark(t *testing.T) {
+	h := prioqueue.NewMaxHeap(0)
+	h.Push(1, 1)
+	h.Push(2, 1)
+
+	r := new(recorder)
+	h.SetObserver(r, 1)
+	h.Push(3, 1)
+	h.Reset()
+	h.Push(4, 1)
+
+	assert.Equal(t, []string{
+		"push 3 size=3",
+		"reset",
+		"push 4 size=1",
+		"high-water mark size=1",
+	}, r.events)
+}
+
+func TestCounters(t *testing.T) {
+	h := prioqueue.NewMinHeap(0)
+	c := new(prioqueue.Counters)
+	h.SetObserver(c, 100)
+
+	for i := 0; i < 200; i++ {
+		h.Push(uint32(i), float32(i))
+	}
+	for i := 0; i < 150; i++ {
+		h.Pop()
+	}
+	h.Reset()
+
+	assert.EqualValues(t, 200, c.Pushes)
+	assert.EqualValues(t, 150, c.Pops)
+	assert.EqualValues(t, 1, c.Resets)
+	assert.EqualValues(t, 1, c.HighWaterMarks)
+	assert.Equal(t, 200, c.MaxLen)
+	assert.Equal(t, cap(h.Items()), c.Cap)
+}
+
+func TestObserver_PushMany(t *testing.T) {
+	h := prioqueue.NewMaxHeap(0)
+	c := new(prioqueue.Counters)
+	h.SetObserver(c, 3)
+
+	h.PushMany([]prioqueue.Item{{ID: 1, Prio: 1}, {ID: 2, Prio: 2}, {ID: 3, Prio: 3}})
+	h.PushMany([]prioqueue.Item{{ID: 4, Prio: 4}})
+	h.PopN(2, nil)
+
+	assert.EqualValues(t, 4, c.Pushes)
+	assert.EqualValues(t, 2, c.Pops)
+	assert.EqualValues(t, 1, c.HighWaterMarks)
+	assert.Equal(t, 4, c.MaxLen)
+}
+
+func TestObserver_Cancel(t *testing.T) {
+	h := prioqueue.NewMaxHeap(0)
+	r := new(recorder)
+	h.SetObserver(r, 2)
+
+	h.Push(1, 1)
+	h.Push(2, 2)
+	h.Cancel(1) // drops below the high-water mark without a pop
+	h.Push(3, 3)
+
+	assert.Equal(t, []string{
+		"push 1 size=1",
+		"push 2 size=2",
+		"high-water mark size=2",
+		"push 3 size=2",
+		"high-water mark size=2",
+	}, r.events)
+}
+
+func TestObserver_Shrink(t *testing.T) {
+	h := prioqueue.NewMinHeap(1024)
+	h.SetAutoShrink(true)
+	for i := 0; i < 1024; i++ {
+		h.Push(uint32(i), float32(i))
+	}
+	for h.Len() > 256 {
+		h.Pop()
+	}
+
+	r := new(recorder)
+	h.SetObserver(r, 0)
+	h.Pop()
+	h.Shrink()
+
+	assert.Equal(t, []string{
+		"shrink size=255 cap=512",
+		"pop 768 size=255",
+		"shrink size=255 cap=255",
+	}, r.events)
+}
+
+func TestCounters_ShrinkCancelled(t *testing.T) {
+	h := prioqueue.NewMinHeap(1024)
+	h.SetAutoShrink(true)
+	for i := 0; i < 1024; i++ {
+		h.Push(uint32(i), float32(i))
+	}
+	for h.Len() > 257 {
+		h.Pop()
+	}
+
+	c := new(prioqueue.Counters)
+	h.SetObserver(c, 0)
+	h.Cancel(767)
+	h.Cancel(768)
+	h.Top() // drops the cancelled items, which shrinks the heap
+
+	assert.Equal(t, 512, cap(h.Items()))
+	assert.Equal(t, cap(h.Items()), c.Cap)
+	assert.Zero(t, c.Pops)
+}
@@ -0,0 +1,22 @@
+package prioqueue
+
+// PopStrategy defines how a MaxHeap or MinHeap restores the heap property
+// after its root node was removed or replaced.
+type PopStrategy int
+
+const (
+	// TopDown moves the new root down the tree. At every level, the node is
+	// compared with the first of its children which is then moved up if it
+	// must be dequeued before the node. This takes two comparisons per level
+	// but stops as soon as the heap property is satisfied. This is the
+	// default strategy.
+	TopDown PopStrategy = iota
+
+	// BottomUp moves the hole which is left by the root down to a leaf by
+	// moving up the first of its children at every level, which only takes
+	// one comparison per level. Then the new root is placed into the hole
+	// and moved up until the heap property is satisfied. Since the last
+	// item of the heap usually belongs close to the leaves, this needs fewer
+	// comparisons than TopDown on average.
+	BottomUp
+)
@@ -0,0 +1,71 @@
+package prioqueuetest
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Bench runs a set of benchmarks against the queues created by the factory.
+// They correspond to the benchmarks of the prioqueue package:
+//   - Push1 tests how fast a single push operation is while the queue is
+//     growing with each iteration of the benchmark.
+//   - Push200 tests how fast 200 items can be pushed into a new queue.
+//   - Pop200 tests how fast all items can be popped from a queue which
+//     contains 200 random items.
+func Bench(b *testing.B, factory Factory) {
+	rng := rand.New(rand.NewSource(42))
+	randValues := make([]float32, 200)
+	for i := range randValues {
+		randValues[i] = rng.Float32()
+	}
+
+	b.Run("Push1", func(b *testing.B) {
+		rng := rand.New(rand.NewSource(42))
+		values := make([]float32, b.N)
+		for i := range values {
+			values[i] = rng.Float32()
+		}
+
+		q := factory()
+		b.ResetTimer()
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			q.Push(uint32(i), values[i])
+		}
+	})
+
+	b.Run("Push200", func(b *testing.B) {
+		q := factory()
+		b.ResetTimer()
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			for id := uint32(0); id < 200; id++ {
+				q.Push(id, randValues[id])
+			}
+
+			b.StopTimer()
+			q = factory()
+			b.StartTimer()
+		}
+	})
+
+	b.Run("Pop200", func(b *testing.B) {
+		q := factory()
+		b.ReportAllocs()
+		b.ResetTimer()
+
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			for id := uint32(0); id < 200; id++ {
+				q.Push(id, randValues[id])
+			}
+			b.StartTimer()
+
+			for q.Len() > 0 {
+				q.Pop()
+			}
+		}
+	})
+}
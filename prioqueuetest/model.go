@@ -0,0 +1,252 @@
+// Package prioqueuetest provides utilities to test implementations of priority
+// queues, such as the queues of the prioqueue package or custom variants of
+// them.
+package prioqueuetest
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+)
+
+// PriorityQueue is the interface of a priority queue that can be checked by
+// this package. It is implemented by prioqueue.MaxHeap and prioqueue.MinHeap.
+type PriorityQueue interface {
+	Push(id uint32, priority float32)
+	Len() int
+	Top() (id uint32, priority float32)
+	Pop() (id uint32, priority float32)
+	PopAndPush(*prioqueue.Item)
+	Reset()
+}
+
+// Order defines in which order a PriorityQueue returns its items.
+type Order int
+
+const (
+	// MaxFirst means items with a high priority are returned first.
+	MaxFirst Order = iota
+
+	// MinFirst means items with a low priority are returned first.
+	MinFirst
+)
+
+// before returns true if an item with priority a must be returned before an
+// item with priority b.
+func (o Order) before(a, b float32) bool {
+	if o == MinFirst {
+		return a < b
+	}
+	return a > b
+}
+
+// OpKind is the kind of an operation on a PriorityQueue.
+type OpKind uint8
+
+// All kinds of operations that can be applied to a PriorityQueue.
+const (
+	OpPush OpKind = iota
+	OpPop
+	OpPopAndPush
+	OpReset
+	numOpKinds
+)
+
+func (k OpKind) String() string {
+	switch k {
+	case OpPush:
+		return "Push"
+	case OpPop:
+		return "Pop"
+	case OpPopAndPush:
+		return "PopAndPush"
+	case OpReset:
+		return "Reset"
+	default:
+		return fmt.Sprintf("OpKind(%d)", uint8(k))
+	}
+}
+
+// Op is an operation on a PriorityQueue. The Item is only used by OpPush and
+// OpPopAndPush.
+type Op struct {
+	Kind OpKind
+	Item prioqueue.Item
+}
+
+func (op Op) String() string {
+	switch op.Kind {
+	case OpPush, OpPopAndPush:
+		return fmt.Sprintf("%s(%d, %v)", op.Kind, op.Item.ID, op.Item.Prio)
+	default:
+		return op.Kind.String() + "()"
+	}
+}
+
+// DecodeOps turns arbitrary bytes into a sequence of operations. This is
+// useful to generate operations from the input of a fuzz test. Each operation
+// is decoded from three bytes, which define the kind of the operation, the ID
+// and the priority. Priorities are small integers so the operations contain
+// many items with equal priority.
+func DecodeOps(data []byte) []Op {
+	ops := make([]Op, 0, len(data)/3)
+	for ; len(data) >= 3; data = data[3:] {
+		ops = append(ops, Op{
+			Kind: OpKind(data[0] % byte(numOpKinds)),
+			Item: prioqueue.Item{
+				ID:   uint32(data[1]),
+				Prio: float32(int8(data[2]) / 4),
+			},
+		})
+	}
+	return ops
+}
+
+// Model is a simple but slow reference implementation of a PriorityQueue
+// which keeps its items in a sorted slice.
+type Model struct {
+	order Order
+	items []prioqueue.Item // sorted in the order in which items are returned
+}
+
+// NewModel returns a new empty Model which returns its items in the given
+// order.
+func NewModel(order Order) *Model {
+	return &Model{order: order}
+}
+
+// Len returns the amount of items in the model.
+func (m *Model) Len() int {
+	return len(m.items)
+}
+
+// Push adds an item to the model.
+func (m *Model) Push(id uint32, priority float32) {
+	i := sort.Search(len(m.items), func(i int) bool {
+		return m.order.before(priority, m.items[i].Prio)
+	})
+
+	m.items = append(m.items, prioqueue.Item{})
+	copy(m.items[i+1:], m.items[i:])
+	m.items[i] = prioqueue.Item{ID: id, Prio: priority}
+}
+
+// Top returns the ID and priority of the item which is returned next. If there
+// are multiple such items, the one that was pushed first is returned.
+func (m *Model) Top() (id uint32, priority float32) {
+	if len(m.items) == 0 {
+		return 0, 0
+	}
+	return m.items[0].ID, m.items[0].Prio
+}
+
+// Pop removes the item which is returned next.
+func (m *Model) Pop() (id uint32, priority float32) {
+	id, priority = m.Top()
+	if len(m.items) > 0 {
+		m.items = m.items[1:]
+	}
+	return id, priority
+}
+
+// PopAndPush removes the next item and adds a new one. If the model is empty,
+// the new item is added nonetheless.
+func (m *Model) PopAndPush(item *prioqueue.Item) {
+	m.Pop()
+	m.Push(item.ID, item.Prio)
+}
+
+// Reset removes all items from the model.
+func (m *Model) Reset() {
+	m.items = m.items[:0]
+}
+
+// isTop returns true if the model contains the given item and no other item
+// must be returned before it.
+func (m *Model) isTop(id uint32, priority float32) bool {
+	for _, item := range m.items {
+		if item.Prio != priority {
+			break
+		}
+		if item.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// remove removes the given item from the model.
+func (m *Model) remove(id uint32, priority float32) {
+	for i, item := range m.items {
+		if item.ID == id && item.Prio == priority {
+			m.items = append(m.items[:i], m.items[i+1:]...)
+			return
+		}
+	}
+}
+
+// Check applies the operations to the queue and to a Model and fails the test
+// as soon as the behavior of the queue differs from the model. The queue must
+// be empty initially.
+//
+// If multiple items have the same priority, the queue may return them in any
+// order. PopAndPush on an empty queue is expected to behave like Push.
+func Check(t testing.TB, q PriorityQueue, order Order, ops []Op) {
+	t.Helper()
+
+	m := NewModel(order)
+	for i, op := range ops {
+		switch op.Kind {
+		case OpPush:
+			q.Push(op.Item.ID, op.Item.Prio)
+			m.Push(op.Item.ID, op.Item.Prio)
+
+		case OpPop:
+			topID, topPrio := q.Top()
+			id, prio := q.Pop()
+			if topID != id || topPrio != prio {
+				t.Fatalf("op %d %v: Top returned (%d, %v) but Pop returned (%d, %v)", i, op, topID, topPrio, id, prio)
+			}
+
+			if m.Len() == 0 {
+				if id != 0 || prio != 0 {
+					t.Fatalf("op %d %v: empty queue returned (%d, %v)", i, op, id, prio)
+				}
+				break
+			}
+
+			if !m.isTop(id, prio) {
+				expectedID, expectedPrio := m.Top()
+				t.Fatalf("op %d %v: returned (%d, %v) but expected (%d, %v)", i, op, id, prio, expectedID, expectedPrio)
+			}
+			m.remove(id, prio)
+
+		case OpPopAndPush:
+			if m.Len() > 0 {
+				id, prio := q.Top()
+				if !m.isTop(id, prio) {
+					expectedID, expectedPrio := m.Top()
+					t.Fatalf("op %d %v: Top returned (%d, %v) but expected (%d, %v)", i, op, id, prio, expectedID, expectedPrio)
+				}
+				m.remove(id, prio)
+			}
+
+			item := op.Item
+			q.PopAndPush(&item)
+			m.Push(item.ID, item.Prio)
+
+		case OpReset:
+			q.Reset()
+			m.Reset()
+
+		default:
+			t.Fatalf("op %d: unknown operation %v", i, op.Kind)
+		}
+
+		if q.Len() != m.Len() {
+			t.Fatalf("op %d %v: queue has length %d but expected %d", i, op, q.Len(), m.Len())
+		}
+	}
+}
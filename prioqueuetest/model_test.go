@@ -0,0 +1,109 @@
+package prioqueuetest_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/fgrosse/prioqueue/prioqueuetest"
+	"github.com/stretchr/testify/assert"
+)
+
+func randomOps(seed int64, n int) []prioqueuetest.Op {
+	rng := rand.New(rand.NewSource(seed))
+	data := make([]byte, 3*n)
+	rng.Read(data)
+	return prioqueuetest.DecodeOps(data)
+}
+
+func TestCheck_MaxHeap(t *testing.T) {
+	for seed := int64(0); seed < 100; seed++ {
+		prioqueuetest.Check(t, prioqueue.NewMaxHeap(0), prioqueuetest.MaxFirst, randomOps(seed, 1000))
+	}
+}
+
+func TestCheck_MinHeap(t *testing.T) {
+	for seed := int64(0); seed < 100; seed++ {
+		prioqueuetest.Check(t, prioqueue.NewMinHeap(0), prioqueuetest.MinFirst, randomOps(seed, 1000))
+	}
+}
+
+func TestCheck_Model(t *testing.T) {
+	for _, order := range []prioqueuetest.Order{prioqueuetest.MaxFirst, prioqueuetest.MinFirst} {
+		prioqueuetest.Check(t, prioqueuetest.NewModel(order), order, randomOps(42, 1000))
+	}
+}
+
+// fatalRecorder is a testing.TB which records calls to Fatalf and then stops
+// the function under test by panicking.
+type fatalRecorder struct {
+	testing.TB
+	msg string
+}
+
+func (r *fatalRecorder) Helper() {}
+
+func (r *fatalRecorder) Fatalf(format string, args ...interface{}) {
+	r.msg = fmt.Sprintf(format, args...)
+	panic(r)
+}
+
+func checkFails(t *testing.T, q prioqueuetest.PriorityQueue, order prioqueuetest.Order, ops []prioqueuetest.Op) string {
+	r := &fatalRecorder{TB: t}
+	func() {
+		defer func() {
+			if v := recover(); v != nil && v != r {
+				panic(v)
+			}
+		}()
+		prioqueuetest.Check(r, q, order, ops)
+	}()
+	return r.msg
+}
+
+func TestCheck_DetectsWrongOrder(t *testing.T) {
+	ops := []prioqueuetest.Op{
+		{Kind: prioqueuetest.OpPush, Item: prioqueue.Item{ID: 1, Prio: 1}},
+		{Kind: prioqueuetest.OpPush, Item: prioqueue.Item{ID: 2, Prio: 2}},
+		{Kind: prioqueuetest.OpPop},
+	}
+
+	msg := checkFails(t, prioqueue.NewMinHeap(0), prioqueuetest.MaxFirst, ops)
+	assert.Equal(t, "op 2 Pop(): returned (1, 1) but expected (2, 2)", msg)
+
+	msg = checkFails(t, prioqueue.NewMaxHeap(0), prioqueuetest.MaxFirst, ops)
+	assert.Empty(t, msg)
+}
+
+func TestCheck_DetectsWrongLength(t *testing.T) {
+	ops := []prioqueuetest.Op{
+		{Kind: prioqueuetest.OpPush, Item: prioqueue.Item{ID: 1, Prio: 1}},
+		{Kind: prioqueuetest.OpPush, Item: prioqueue.Item{ID: 2, Prio: 2}},
+	}
+
+	msg := checkFails(t, &droppingQueue{MaxHeap: prioqueue.NewMaxHeap(0)}, prioqueuetest.MaxFirst, ops)
+	assert.Equal(t, "op 1 Push(2, 2): queue has length 1 but expected 2", msg)
+}
+
+// droppingQueue is a broken PriorityQueue which ignores every second push.
+type droppingQueue struct {
+	*prioqueue.MaxHeap
+	pushes int
+}
+
+func (q *droppingQueue) Push(id uint32, priority float32) {
+	q.pushes++
+	if q.pushes%2 == 1 {
+		q.MaxHeap.Push(id, priority)
+	}
+}
+
+func TestDecodeOps(t *testing.T) {
+	ops := prioqueuetest.DecodeOps([]byte{0, 1, 8, 1, 2, 3, 6, 3, 252, 7, 0})
+	assert.Equal(t, []prioqueuetest.Op{
+		{Kind: prioqueuetest.OpPush, Item: prioqueue.Item{ID: 1, Prio: 2}},
+		{Kind: prioqueuetest.OpPop, Item: prioqueue.Item{ID: 2, Prio: 0}},
+		{Kind: prioqueuetest.OpPopAndPush, Item: prioqueue.Item{ID: 3, Prio: -1}},
+	}, ops)
+}
@@ -0,0 +1,171 @@
+package prioqueuetest
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+)
+
+// Factory creates a new empty PriorityQueue.
+type Factory func() PriorityQueue
+
+// Run runs a suite of tests against the queues created by the factory. Each
+// test uses a new queue. The order defines whether the queue is expected to
+// return the items with the highest or the lowest priority first.
+//
+// Run expects that Top and Pop return (0, 0) on an empty queue and that
+// PopAndPush on an empty queue behaves like Push.
+func Run(t *testing.T, factory Factory, order Order) {
+	t.Run("Order", func(t *testing.T) {
+		testOrder(t, factory(), order)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		testEmpty(t, factory())
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		testReset(t, factory(), order)
+	})
+
+	t.Run("PopAndPushEmpty", func(t *testing.T) {
+		testPopAndPushEmpty(t, factory())
+	})
+
+	t.Run("Random", func(t *testing.T) {
+		testRandom(t, factory(), order, 10_000)
+	})
+
+	t.Run("RandomOps", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(42))
+		data := make([]byte, 3*10_000)
+		rng.Read(data)
+		Check(t, factory(), order, DecodeOps(data))
+	})
+}
+
+// testOrder pushes a fixed set of items in random order and checks that they
+// are returned in the expected order.
+func testOrder(t *testing.T, q PriorityQueue, order Order) {
+	items := []prioqueue.Item{
+		{ID: 1, Prio: 10},
+		{ID: 2, Prio: 20},
+		{ID: 3, Prio: 30},
+		{ID: 4, Prio: 40},
+		{ID: 5, Prio: 50},
+		{ID: 6, Prio: 60},
+		{ID: 7, Prio: 70},
+		{ID: 8, Prio: 80},
+		{ID: 9, Prio: 90},
+		{ID: 10, Prio: 100},
+	}
+
+	rng := rand.New(rand.NewSource(42))
+	for _, i := range rng.Perm(len(items)) {
+		q.Push(items[i].ID, items[i].Prio)
+	}
+
+	if q.Len() != 10 {
+		t.Fatalf("Len() = %d after pushing 10 items", q.Len())
+	}
+
+	q.PopAndPush(&prioqueue.Item{ID: 11, Prio: 55})
+	if q.Len() != 10 {
+		t.Fatalf("Len() = %d after PopAndPush", q.Len())
+	}
+
+	var expected []uint32
+	if order == MaxFirst {
+		expected = []uint32{9, 8, 7, 6, 11, 5, 4, 3, 2, 1}
+	} else {
+		expected = []uint32{2, 3, 4, 5, 11, 6, 7, 8, 9, 10}
+	}
+
+	for i, expectedID := range expected {
+		topID, topPrio := q.Top()
+		id, prio := q.Pop()
+		if topID != id || topPrio != prio {
+			t.Errorf("Top returned (%d, %v) but Pop returned (%d, %v)", topID, topPrio, id, prio)
+		}
+		if id != expectedID {
+			t.Errorf("Pop #%d returned ID %d but expected %d", i+1, id, expectedID)
+		}
+	}
+
+	if q.Len() != 0 {
+		t.Errorf("Len() = %d after popping all items", q.Len())
+	}
+}
+
+// testEmpty checks that an empty queue does not panic.
+func testEmpty(t *testing.T, q PriorityQueue) {
+	if q.Len() != 0 {
+		t.Fatalf("new queue has length %d", q.Len())
+	}
+
+	if id, prio := q.Top(); id != 0 || prio != 0 {
+		t.Errorf("Top() = (%d, %v) on empty queue", id, prio)
+	}
+
+	if id, prio := q.Pop(); id != 0 || prio != 0 {
+		t.Errorf("Pop() = (%d, %v) on empty queue", id, prio)
+	}
+
+	q.Reset()
+	if q.Len() != 0 {
+		t.Errorf("Len() = %d after Reset", q.Len())
+	}
+}
+
+// testReset checks that the queue can be used normally after it was reset.
+func testReset(t *testing.T, q PriorityQueue, order Order) {
+	for i := uint32(0); i < 100; i++ {
+		q.Push(i, float32(i))
+	}
+
+	q.Reset()
+	if q.Len() != 0 {
+		t.Fatalf("Len() = %d after Reset", q.Len())
+	}
+
+	testRandom(t, q, order, 100)
+}
+
+// testPopAndPushEmpty checks that PopAndPush on an empty queue adds the item.
+func testPopAndPushEmpty(t *testing.T, q PriorityQueue) {
+	q.PopAndPush(&prioqueue.Item{ID: 42, Prio: 1.5})
+	if q.Len() != 1 {
+		t.Fatalf("Len() = %d after PopAndPush on empty queue", q.Len())
+	}
+
+	if id, prio := q.Pop(); id != 42 || prio != 1.5 {
+		t.Errorf("Pop() = (%d, %v) but expected (42, 1.5)", id, prio)
+	}
+}
+
+// testRandom pushes n items with random priorities and checks that they are
+// popped in the expected order.
+func testRandom(t *testing.T, q PriorityQueue, order Order, n int) {
+	rng := rand.New(rand.NewSource(42))
+	for i := 0; i < n; i++ {
+		q.Push(uint32(i), rng.Float32())
+	}
+
+	if q.Len() != n {
+		t.Fatalf("Len() = %d after pushing %d items", q.Len(), n)
+	}
+
+	var last float32
+	for i := 0; q.Len() > 0; i++ {
+		topID, topPrio := q.Top()
+		id, prio := q.Pop()
+		if topID != id || topPrio != prio {
+			t.Errorf("Top returned (%d, %v) but Pop returned (%d, %v)", topID, topPrio, id, prio)
+		}
+		if i > 0 && order.before(prio, last) {
+			t.Errorf("Incorrect order: last %v popped=%v", last, prio)
+		}
+		last = prio
+	}
+}
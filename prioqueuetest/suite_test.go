@@ -0,0 +1,16 @@
+package prioqueuetest_test
+
+import (
+	"testing"
+
+	"github.com/fgrosse/prioqueue/prioqueuetest"
+)
+
+func TestRun_Model(t *testing.T) {
+	for _, order := range []prioqueuetest.Order{prioqueuetest.MaxFirst, prioqueuetest.MinFirst} {
+		order := order
+		prioqueuetest.Run(t, func() prioqueuetest.PriorityQueue {
+			return prioqueuetest.NewModel(order)
+		}, order)
+	}
+}
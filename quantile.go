@@ -0,0 +1,145 @@
+package prioqueue
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Quantile tracks the q-quantile (e.g. the 95th percentile) of a stream of
+// values using a bounded amount of memory. Like the RunningMedian, it keeps
+// all values below and at the quantile in a MaxHeap and the values above it in
+// a MinHeap so the quantile can be read from the top of the MaxHeap.
+//
+// The quantile is defined via the nearest rank method, i.e. it is the smallest
+// value such that at least a fraction of q of all values are less than or
+// equal to it.
+//
+// At most size values are held in memory. As long as no more than size values
+// have been added, the quantile is exact. Beyond that, the Quantile keeps a
+// uniform random sample of size values from the stream (reservoir sampling)
+// and returns the quantile of this sample. The fraction of values in the
+// stream which are less than or equal to the estimate then has a standard
+// deviation of about sqrt(q*(1-q)/size) around q. For instance, tracking the
+// 99th percentile with a size of 10000 yields a value between the 98.8th and
+// the 99.2th percentile with a probability of about 95%.
+//
+// Time Complexity
+//
+//   Add takes amortized O(log size) and Value happens in constant time.
+type Quantile struct {
+	q    float64
+	size int
+
+	heaps  splitHeaps
+	sample []uint32 // IDs of the values in the heaps in the order they were added
+	count  uint64   // amount of values that have been added so far
+	nextID uint32
+	rng    *rand.Rand
+}
+
+// NewQuantile returns a new Quantile instance which tracks the q-quantile of a
+// stream of values and holds at most size values in memory. NewQuantile panics
+// if q is not between 0 and 1 or if size is less than 1.
+func NewQuantile(q float64, size int) *Quantile {
+	if q < 0 || q > 1 || math.IsNaN(q) {
+		panic("prioqueue: quantile must be between 0 and 1")
+	}
+	if size < 1 {
+		panic("prioqueue: quantile size must be at least 1")
+	}
+
+	return &Quantile{
+		q:      q,
+		size:   size,
+		sample: make([]uint32, 0, size),
+		rng:    rand.New(rand.NewSource(1)),
+	}
+}
+
+// Add adds a value to the stream.
+func (e *Quantile) Add(value float32) {
+	if e.nextID == math.MaxUint32 {
+		e.renumber()
+	}
+
+	e.count++
+
+	if len(e.sample) < e.size {
+		e.sample = append(e.sample, e.add(value))
+		e.rebalance()
+		return
+	}
+
+	// Reservoir sampling: the new value replaces a random value of the sample
+	// with probability size/count.
+	j := e.rng.Int63n(int64(e.count))
+	if j >= int64(e.size) {
+		return
+	}
+
+	e.heaps.remove(e.sample[j])
+	e.sample[j] = e.add(value)
+	e.rebalance()
+}
+
+// Value returns the quantile of all values that have been added so far, or an
+// estimate of it if more than size values have been added. If no value has
+// been added yet, Value returns 0.
+func (e *Quantile) Value() float32 {
+	_, v := e.heaps.lower.Top()
+	return v
+}
+
+// Count returns the amount of values that have been added so far.
+func (e *Quantile) Count() uint64 {
+	return e.count
+}
+
+// Exact returns true if the value returned by Value is the exact quantile and
+// not an estimate.
+func (e *Quantile) Exact() bool {
+	return e.count <= uint64(e.size)
+}
+
+// add adds a value to the heaps and returns its ID.
+func (e *Quantile) add(value float32) uint32 {
+	id := e.nextID
+	e.nextID++
+	e.heaps.add(id, value)
+	return id
+}
+
+// rebalance moves values between the two heaps until the top of the MaxHeap is
+// the value with the nearest rank of the quantile.
+func (e *Quantile) rebalance() {
+	n := e.heaps.len()
+	rank := int(math.Ceil(e.q * float64(n)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	e.heaps.rebalance(rank)
+}
+
+// renumber assigns new IDs to all values in the sample, starting at 0. This
+// happens before the IDs overflow, since very old values may still be part of
+// the sample and their IDs must not be assigned again.
+func (e *Quantile) renumber() {
+	values := make(map[uint32]float32, len(e.sample))
+	for _, item := range e.heaps.lower.Items() {
+		values[item.ID] = item.Prio
+	}
+	for _, item := range e.heaps.upper.Items() {
+		values[item.ID] = item.Prio
+	}
+
+	// The heaps may still contain cancelled items but the IDs in the sample
+	// always refer to the values which are still alive.
+	e.heaps = splitHeaps{}
+	e.nextID = 0
+	for i, id := range e.sample {
+		e.sample[i] = e.nextID
+		e.heaps.add(e.nextID, values[id])
+		e.nextID++
+	}
+}
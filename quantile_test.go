@@ -0,0 +1,97 @@
+package prioqueue_test
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/stretchr/testify/assert"
+)
+
+// sortedQuantile returns the q-quantile of the values using the nearest rank
+// method.
+func sortedQuantile(values []float32, q float64) float32 {
+	sorted := append([]float32(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(q * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func TestQuantile_Exact(t *testing.T) {
+	for _, q := range []float64{0, 0.1, 0.5, 0.95, 0.99, 1} {
+		e := prioqueue.NewQuantile(q, 1000)
+		assert.EqualValues(t, 0, e.Value())
+
+		rng := rand.New(rand.NewSource(42))
+		var values []float32
+		for i := 0; i < 1000; i++ {
+			v := float32(rng.Intn(500))
+			values = append(values, v)
+			e.Add(v)
+
+			assert.True(t, e.Exact())
+			assert.Equal(t, sortedQuantile(values, q), e.Value(), "q=%v after %d values", q, len(values))
+		}
+	}
+}
+
+func TestQuantile_Estimate(t *testing.T) {
+	const (
+		n    = 100_000
+		size = 2000
+	)
+
+	for _, q := range []float64{0.5, 0.95, 0.99} {
+		e := prioqueue.NewQuantile(q, size)
+
+		rng := rand.New(rand.NewSource(42))
+		values := make([]float32, n)
+		for i := range values {
+			values[i] = rng.Float32()
+			e.Add(values[i])
+		}
+
+		assert.False(t, e.Exact())
+		assert.EqualValues(t, n, e.Count())
+
+		// The fraction of values which are less than or equal to the estimate
+		// should be close to q. The tolerance is about four standard deviations.
+		estimate := e.Value()
+		var rank int
+		for _, v := range values {
+			if v <= estimate {
+				rank++
+			}
+		}
+
+		tolerance := 4 * math.Sqrt(q*(1-q)/size)
+		assert.InDelta(t, q, float64(rank)/n, tolerance, "q=%v", q)
+	}
+}
+
+func TestQuantile_SortedInput(t *testing.T) {
+	e := prioqueue.NewQuantile(0.9, 100)
+	for i := 1; i <= 100; i++ {
+		e.Add(float32(i))
+	}
+	assert.EqualValues(t, 90, e.Value())
+
+	for i := 100; i > 0; i-- {
+		e.Add(float32(i))
+	}
+	assert.False(t, e.Exact())
+	assert.InDelta(t, 90, e.Value(), 10)
+}
+
+func TestNewQuantile_InvalidArguments(t *testing.T) {
+	assert.Panics(t, func() { prioqueue.NewQuantile(-0.1, 10) })
+	assert.Panics(t, func() { prioqueue.NewQuantile(1.1, 10) })
+	assert.Panics(t, func() { prioqueue.NewQuantile(math.NaN(), 10) })
+	assert.Panics(t, func() { prioqueue.NewQuantile(0.5, 0) })
+}
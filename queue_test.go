@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/fgrosse/prioqueue"
+	"github.com/fgrosse/prioqueue/prioqueuetest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -110,3 +111,15 @@ func runTestsN(t *testing.T, pq PriorityQueue, checkOrder orderFunc, n int) {
 	}
 	assert.Equal(t, 0, pq.Len())
 }
+
+// checkRandomOps applies random operations to the empty queue and checks that
+// it behaves like a prioqueuetest.Model.
+func checkRandomOps(t *testing.T, pq prioqueuetest.PriorityQueue, order prioqueuetest.Order) {
+	t.Helper()
+
+	rng := rand.New(rand.NewSource(42))
+	data := make([]byte, 3*1000)
+	rng.Read(data)
+
+	prioqueuetest.Check(t, pq, order, prioqueuetest.DecodeOps(data))
+}
@@ -0,0 +1,145 @@
+package prioqueue
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// ErrNonMonotonic is returned when pushing a key into a RadixHeap which is
+// smaller than the key that was last returned by the heap.
+var ErrNonMonotonic = errors.New("prioqueue: key is smaller than the last key returned by the heap")
+
+// RadixHeap implements a monotone priority queue for integer keys which allows
+// to retrieve the item with the lowest key. Monotone means that the key of a
+// pushed item must never be smaller than the key that was last returned by Pop
+// or Top. This is the case for many algorithms, such as Dijkstra's shortest
+// paths with integer edge weights or event simulations.
+//
+// Instead of comparing items, the RadixHeap distributes them into 65 buckets
+// based on the highest bit in which their key differs from the last returned
+// key. Bucket 0 contains the items whose key is equal to the last returned key
+// and bucket i contains the items whose key differs in bit i-1 first. When the
+// lowest bucket is empty, the next non-empty bucket is redistributed into the
+// lower buckets. Each item can move to a lower bucket at most 64 times.
+//
+// Both uint32 and uint64 keys are supported since uint32 values can be
+// converted to uint64 without loss.
+//
+// Items with equal keys are not returned in any particular order.
+//
+// Time Complexity
+//
+//   Push takes O(1) and Pop takes amortized O(log C) where C is the largest key.
+//   Top takes amortized O(log C) if it follows a Pop and otherwise constant time.
+type RadixHeap struct {
+	buckets [65][]radixItem
+	last    uint64 // the key last returned by Pop or Top
+	len     int
+}
+
+type radixItem struct {
+	id  uint32
+	key uint64
+}
+
+// NewRadixHeap returns a new RadixHeap instance.
+func NewRadixHeap() *RadixHeap {
+	return new(RadixHeap)
+}
+
+// Len returns the amount of elements in the queue.
+func (h *RadixHeap) Len() int {
+	return h.len
+}
+
+// Reset empties the queue. After a reset, any key can be pushed again. Note
+// that the memory of the buckets is not released.
+func (h *RadixHeap) Reset() {
+	for i := range h.buckets {
+		h.buckets[i] = h.buckets[i][:0]
+	}
+	h.last = 0
+	h.len = 0
+}
+
+// Push adds an item with the given ID and key to the queue. It returns
+// ErrNonMonotonic if the key is smaller than the key that was last returned by
+// Pop or Top.
+func (h *RadixHeap) Push(id uint32, key uint64) error {
+	if key < h.last {
+		return ErrNonMonotonic
+	}
+
+	b := h.bucket(key)
+	h.buckets[b] = append(h.buckets[b], radixItem{id: id, key: key})
+	h.len++
+	return nil
+}
+
+// Top returns the ID and key of the item with the lowest key in the queue
+// without removing it.
+func (h *RadixHeap) Top() (id uint32, key uint64) {
+	if h.len == 0 {
+		return 0, 0
+	}
+
+	h.refill()
+	item := h.buckets[0][len(h.buckets[0])-1]
+	return item.id, item.key
+}
+
+// Pop removes the item with the lowest key from the queue and returns its ID
+// and key.
+func (h *RadixHeap) Pop() (id uint32, key uint64) {
+	if h.len == 0 {
+		return 0, 0
+	}
+
+	h.refill()
+	n := len(h.buckets[0]) - 1
+	item := h.buckets[0][n]
+	h.buckets[0] = h.buckets[0][:n]
+	h.len--
+
+	return item.id, item.key
+}
+
+// refill makes sure that bucket 0 is not empty by redistributing the first
+// non-empty bucket. The heap must not be empty.
+func (h *RadixHeap) refill() {
+	if len(h.buckets[0]) > 0 {
+		return
+	}
+
+	i := 1
+	for len(h.buckets[i]) == 0 {
+		i++
+	}
+
+	// The smallest key in bucket i becomes the new reference key. All other
+	// keys in this bucket differ from it in a lower bit than i-1, so each of
+	// them moves to a lower bucket.
+	items := h.buckets[i]
+	min := items[0].key
+	for _, item := range items[1:] {
+		if item.key < min {
+			min = item.key
+		}
+	}
+
+	h.last = min
+	for _, item := range items {
+		b := h.bucket(item.key)
+		h.buckets[b] = append(h.buckets[b], item)
+	}
+
+	h.buckets[i] = items[:0]
+}
+
+// bucket returns the index of the bucket for the given key.
+func (h *RadixHeap) bucket(key uint64) int {
+	if key == h.last {
+		return 0
+	}
+	return 64 - bits.LeadingZeros64(key^h.last)
+}
@@ -0,0 +1,84 @@
+package prioqueue_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRadixHeap(t *testing.T) {
+	var h prioqueue.RadixHeap
+
+	id, key := h.Pop()
+	assert.EqualValues(t, 0, id)
+	assert.EqualValues(t, 0, key)
+	id, key = h.Top()
+	assert.EqualValues(t, 0, id)
+	assert.EqualValues(t, 0, key)
+
+	keys := []uint64{50, 10, 1 << 40, 30, 10, 1<<64 - 1, 0, 20}
+	for i, key := range keys {
+		require.NoError(t, h.Push(uint32(i), key))
+	}
+	require.Equal(t, len(keys), h.Len())
+
+	sorted := append([]uint64(nil), keys...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	for _, expected := range sorted {
+		topID, topKey := h.Top()
+		id, key := h.Pop()
+		assert.Equal(t, topID, id)
+		assert.Equal(t, topKey, key)
+		assert.Equal(t, expected, key)
+		assert.Equal(t, keys[id], key)
+	}
+	assert.Equal(t, 0, h.Len())
+}
+
+func TestRadixHeap_NonMonotonic(t *testing.T) {
+	h := prioqueue.NewRadixHeap()
+	require.NoError(t, h.Push(1, 10))
+	require.NoError(t, h.Push(2, 20))
+
+	h.Pop()
+	assert.Equal(t, prioqueue.ErrNonMonotonic, h.Push(3, 9))
+	assert.NoError(t, h.Push(3, 10), "the last key can be pushed again")
+	assert.Equal(t, 2, h.Len())
+
+	_, key := h.Top()
+	assert.EqualValues(t, 10, key)
+
+	h.Reset()
+	assert.Equal(t, 0, h.Len())
+	assert.NoError(t, h.Push(4, 0), "any key can be pushed after a reset")
+}
+
+func TestRadixHeap_Random(t *testing.T) {
+	h := prioqueue.NewRadixHeap()
+	model := prioqueue.NewMinHeap(0)
+	rng := rand.New(rand.NewSource(42))
+
+	var last uint64
+	for i := 0; i < 100_000; i++ {
+		if rng.Intn(3) > 0 || h.Len() == 0 {
+			// Push a key which is a bit larger than the last popped key,
+			// similar to Dijkstra's algorithm.
+			key := last + uint64(rng.Intn(1000))
+			require.NoError(t, h.Push(uint32(i), key))
+			model.Push(uint32(i), float32(key))
+			continue
+		}
+
+		_, key := h.Pop()
+		_, expected := model.Pop()
+		require.Equal(t, expected, float32(key))
+		require.GreaterOrEqual(t, key, last)
+		last = key
+		require.Equal(t, model.Len(), h.Len())
+	}
+}
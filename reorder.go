@@ -0,0 +1,77 @@
+package prioqueue
+
+// Reorder reads items from the input channel and emits them on the returned
+// channel in order of their priority. Up to buffer items are held in a MaxHeap
+// and whenever the consumer is ready to receive, the item with the highest
+// priority among them is emitted. If the buffer is full, Reorder stops reading
+// from the input channel until the consumer received the next item, which
+// applies backpressure to the producer. A buffer size less than 1 is treated
+// as 1.
+//
+// Note that an item can only be reordered relative to the items which are in
+// the buffer at the same time. If the consumer is faster than the producer,
+// items are emitted in the order in which they arrive.
+//
+// When the input channel is closed, all buffered items are emitted in order of
+// their priority and then the returned channel is closed. The consumer must
+// receive all items until the channel is closed, otherwise the goroutine which
+// runs the reordering is leaked.
+func Reorder(in <-chan Item, buffer int) <-chan Item {
+	if buffer < 1 {
+		buffer = 1
+	}
+
+	out := make(chan Item)
+	go reorder(in, out, buffer)
+	return out
+}
+
+func reorder(in <-chan Item, out chan<- Item, buffer int) {
+	defer close(out)
+
+	h := NewMaxHeap(buffer)
+	for in != nil || h.Len() > 0 {
+		// A nil channel blocks forever, which disables the corresponding case
+		// of the select statements below.
+		var recv <-chan Item
+		if in != nil && h.Len() < buffer {
+			recv = in
+		}
+
+		var (
+			send chan<- Item
+			top  Item
+		)
+		if h.Len() > 0 {
+			send = out
+			top = *h.TopItem()
+		}
+
+		// Prefer to read all available items before emitting the next one, so
+		// the emitted item has the highest priority among as many items as
+		// possible.
+		if recv != nil && send != nil {
+			select {
+			case item, ok := <-recv:
+				if !ok {
+					in = nil
+				} else {
+					h.Push(item.ID, item.Prio)
+				}
+				continue
+			default:
+			}
+		}
+
+		select {
+		case item, ok := <-recv:
+			if !ok {
+				in = nil
+				continue
+			}
+			h.Push(item.ID, item.Prio)
+		case send <- top:
+			h.Release(h.PopItem())
+		}
+	}
+}
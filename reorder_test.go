@@ -0,0 +1,65 @@
+package prioqueue_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReorder(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	items := make([]prioqueue.Item, 100)
+	in := make(chan prioqueue.Item, len(items))
+	for i := range items {
+		items[i] = prioqueue.Item{ID: uint32(i), Prio: rng.Float32()}
+		in <- items[i]
+	}
+	close(in)
+
+	var actual []prioqueue.Item
+	for item := range prioqueue.Reorder(in, len(items)) {
+		actual = append(actual, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Prio > items[j].Prio
+	})
+
+	assert.Equal(t, items, actual)
+}
+
+func TestReorder_Backpressure(t *testing.T) {
+	in := make(chan prioqueue.Item)
+	out := prioqueue.Reorder(in, 2)
+
+	in <- prioqueue.Item{ID: 1, Prio: 1}
+	in <- prioqueue.Item{ID: 2, Prio: 2}
+
+	select {
+	case in <- prioqueue.Item{ID: 3, Prio: 3}:
+		t.Fatal("Reorder should not read more items than fit into its buffer")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	assert.Equal(t, prioqueue.Item{ID: 2, Prio: 2}, <-out)
+	in <- prioqueue.Item{ID: 3, Prio: 3}
+	close(in)
+
+	assert.Equal(t, prioqueue.Item{ID: 3, Prio: 3}, <-out)
+	assert.Equal(t, prioqueue.Item{ID: 1, Prio: 1}, <-out)
+
+	_, ok := <-out
+	assert.False(t, ok)
+}
+
+func TestReorder_Empty(t *testing.T) {
+	in := make(chan prioqueue.Item)
+	close(in)
+
+	_, ok := <-prioqueue.Reorder(in, 0)
+	assert.False(t, ok)
+}
@@ -0,0 +1,203 @@
+// Package sim implements a discrete-event simulation engine which uses a
+// prioqueue.MinHeap to order events by their simulated time.
+package sim
+
+import (
+	"math"
+
+	"github.com/fgrosse/prioqueue"
+)
+
+// EventID identifies a scheduled event.
+type EventID uint64
+
+// Engine schedules callbacks at virtual points in time and runs them in
+// chronological order. Events which are scheduled at the same time run in the
+// order in which they were scheduled.
+//
+// Simulated time is represented as float32 since it is used as the priority of
+// the events in the queue. Note that a float32 can represent all integers only
+// up to 2^24, so time steps should be chosen accordingly.
+//
+// An Engine is not safe for concurrent use by multiple goroutines.
+type Engine struct {
+	now     float32
+	stopped bool
+
+	queue   *prioqueue.MinHeap
+	events  map[uint32]*event  // pending events by their sequence number
+	seqs    map[EventID]uint32 // sequence number of each pending event
+	nextSeq uint32
+	nextID  EventID
+}
+
+// event is a scheduled callback. Its sequence number is used as ID in the
+// queue which makes events at the same time run in the order they were
+// scheduled.
+type event struct {
+	id EventID
+	fn func(*Engine)
+}
+
+// NewEngine returns a new Engine whose clock starts at 0.
+func NewEngine() *Engine {
+	queue := prioqueue.NewMinHeap(0)
+	queue.SetTieBreakByID(true)
+
+	return &Engine{
+		queue:  queue,
+		events: map[uint32]*event{},
+		seqs:   map[EventID]uint32{},
+	}
+}
+
+// Now returns the current simulated time.
+func (e *Engine) Now() float32 {
+	return e.now
+}
+
+// Pending returns the amount of events which are scheduled but did not run yet.
+func (e *Engine) Pending() int {
+	return len(e.events)
+}
+
+// Schedule schedules fn to run at the given point in time and returns an ID
+// which can be used to cancel or reschedule the event. Schedule panics if the
+// time lies in the past.
+func (e *Engine) Schedule(at float32, fn func(*Engine)) EventID {
+	if at < e.now || math.IsNaN(float64(at)) {
+		panic("sim: cannot schedule an event in the past")
+	}
+
+	id := e.nextID
+	e.nextID++
+	e.push(&event{id: id, fn: fn}, at)
+
+	return id
+}
+
+// After schedules fn to run after the given delay. It is a shorthand for
+// Schedule(e.Now()+delay, fn).
+func (e *Engine) After(delay float32, fn func(*Engine)) EventID {
+	return e.Schedule(e.now+delay, fn)
+}
+
+// Cancel removes a scheduled event so it will not run. It returns false if the
+// event already ran or has been cancelled before.
+func (e *Engine) Cancel(id EventID) bool {
+	seq, ok := e.seqs[id]
+	if !ok {
+		return false
+	}
+
+	delete(e.seqs, id)
+	delete(e.events, seq)
+	e.queue.Cancel(seq)
+	return true
+}
+
+// Reschedule moves a scheduled event to a new point in time. The event keeps
+// its ID but runs after all other events which are already scheduled at the
+// same time. Reschedule returns false if the event already ran or has been
+// cancelled. Like Schedule, it panics if the time lies in the past.
+func (e *Engine) Reschedule(id EventID, at float32) bool {
+	if at < e.now || math.IsNaN(float64(at)) {
+		panic("sim: cannot schedule an event in the past")
+	}
+
+	seq, ok := e.seqs[id]
+	if !ok {
+		return false
+	}
+
+	ev := e.events[seq]
+	e.Cancel(id)
+	e.push(ev, at)
+	return true
+}
+
+// Run runs all events which are scheduled at or before the horizon in
+// chronological order. Events may schedule new events while they are running.
+// Afterwards, the clock of the engine is advanced to the horizon.
+//
+// If an event calls Stop, Run returns immediately after that event and the
+// clock stays at the time of the event.
+func (e *Engine) Run(horizon float32) {
+	e.stopped = false
+	for !e.stopped {
+		_, at := e.queue.Top()
+		if e.queue.Len() == 0 || at > horizon {
+			break
+		}
+
+		e.Step()
+	}
+
+	if !e.stopped && horizon > e.now {
+		e.now = horizon
+	}
+}
+
+// Step runs the next scheduled event and advances the clock to its time. It
+// returns false if there are no events.
+func (e *Engine) Step() bool {
+	item := e.queue.PopItem()
+	if item == nil {
+		return false
+	}
+
+	seq, at := item.ID, item.Prio
+	e.queue.Release(item)
+
+	ev := e.events[seq]
+	delete(e.events, seq)
+	delete(e.seqs, ev.id)
+
+	e.now = at
+	ev.fn(e)
+	return true
+}
+
+// Stop makes Run return after the currently running event.
+func (e *Engine) Stop() {
+	e.stopped = true
+}
+
+// push adds an event to the queue using the next sequence number.
+func (e *Engine) push(ev *event, at float32) {
+	if e.nextSeq == math.MaxUint32 {
+		e.renumber()
+	}
+
+	seq := e.nextSeq
+	e.nextSeq++
+
+	e.events[seq] = ev
+	e.seqs[ev.id] = seq
+	e.queue.Push(seq, at)
+}
+
+// renumber assigns new sequence numbers to all pending events, starting at 0,
+// before the sequence numbers overflow. The relative order of all events is
+// preserved since they are popped from the queue in order.
+func (e *Engine) renumber() {
+	queue := prioqueue.NewMinHeap(e.queue.Len())
+	queue.SetTieBreakByID(true)
+	events := make(map[uint32]*event, len(e.events))
+
+	var seq uint32
+	for e.queue.Len() > 0 {
+		item := e.queue.PopItem()
+		ev := e.events[item.ID]
+
+		item.ID = seq
+		queue.PushItem(item)
+		events[seq] = ev
+		e.seqs[ev.id] = seq
+		seq++
+	}
+
+	e.queue = queue
+	e.events = events
+	e.nextSeq = seq
+}
@@ -0,0 +1,164 @@
+package sim_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fgrosse/prioqueue/sim"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine_Run(t *testing.T) {
+	e := sim.NewEngine()
+
+	var log []string
+	record := func(name string) func(*sim.Engine) {
+		return func(e *sim.Engine) {
+			log = append(log, fmt.Sprintf("%s@%.0f", name, e.Now()))
+		}
+	}
+
+	e.Schedule(5, record("c"))
+	e.Schedule(1, record("a"))
+	e.Schedule(3, record("b"))
+	e.Schedule(10, record("d"))
+	assert.Equal(t, 4, e.Pending())
+
+	e.Run(5)
+	assert.Equal(t, []string{"a@1", "b@3", "c@5"}, log)
+	assert.EqualValues(t, 5, e.Now())
+	assert.Equal(t, 1, e.Pending())
+
+	e.Run(8)
+	assert.EqualValues(t, 8, e.Now(), "clock should advance to the horizon")
+	assert.Equal(t, []string{"a@1", "b@3", "c@5"}, log)
+
+	e.Run(20)
+	assert.Equal(t, []string{"a@1", "b@3", "c@5", "d@10"}, log)
+	assert.EqualValues(t, 20, e.Now())
+	assert.Equal(t, 0, e.Pending())
+	assert.False(t, e.Step())
+}
+
+func TestEngine_SimultaneousEvents(t *testing.T) {
+	e := sim.NewEngine()
+
+	var order []int
+	for i := 0; i < 100; i++ {
+		i := i
+		e.Schedule(float32(i%3), func(*sim.Engine) {
+			order = append(order, i)
+		})
+	}
+
+	// Events which are scheduled by other events at the current time run
+	// after all events which were already scheduled at that time.
+	e.Schedule(1, func(e *sim.Engine) {
+		e.After(0, func(*sim.Engine) { order = append(order, -1) })
+	})
+
+	e.Run(10)
+
+	var expected []int
+	for r := 0; r < 3; r++ {
+		for i := r; i < 100; i += 3 {
+			expected = append(expected, i)
+		}
+		if r == 1 {
+			expected = append(expected, -1)
+		}
+	}
+
+	assert.Equal(t, expected, order)
+}
+
+func TestEngine_Cancel(t *testing.T) {
+	e := sim.NewEngine()
+
+	var ran []string
+	a := e.Schedule(1, func(*sim.Engine) { ran = append(ran, "a") })
+	b := e.Schedule(2, func(*sim.Engine) { ran = append(ran, "b") })
+	e.Schedule(3, func(e *sim.Engine) {
+		ran = append(ran, "c")
+		assert.False(t, e.Cancel(b), "b already ran")
+	})
+
+	assert.True(t, e.Cancel(a))
+	assert.False(t, e.Cancel(a))
+	assert.Equal(t, 2, e.Pending())
+
+	e.Run(10)
+	assert.Equal(t, []string{"b", "c"}, ran)
+}
+
+func TestEngine_Reschedule(t *testing.T) {
+	e := sim.NewEngine()
+
+	var ran []string
+	a := e.Schedule(1, func(e *sim.Engine) { ran = append(ran, fmt.Sprintf("a@%.0f", e.Now())) })
+	e.Schedule(2, func(e *sim.Engine) { ran = append(ran, fmt.Sprintf("b@%.0f", e.Now())) })
+	e.Schedule(3, func(e *sim.Engine) { ran = append(ran, fmt.Sprintf("c@%.0f", e.Now())) })
+
+	assert.True(t, e.Reschedule(a, 3))
+	assert.Equal(t, 3, e.Pending())
+
+	e.Run(10)
+	assert.Equal(t, []string{"b@2", "c@3", "a@3"}, ran)
+	assert.False(t, e.Reschedule(a, 20))
+}
+
+func TestEngine_Stop(t *testing.T) {
+	e := sim.NewEngine()
+
+	var ran int
+	for i := 1; i <= 5; i++ {
+		e.Schedule(float32(i), func(e *sim.Engine) {
+			ran++
+			if e.Now() == 3 {
+				e.Stop()
+			}
+		})
+	}
+
+	e.Run(10)
+	assert.Equal(t, 3, ran)
+	assert.EqualValues(t, 3, e.Now())
+
+	e.Run(10)
+	assert.Equal(t, 5, ran)
+	assert.EqualValues(t, 10, e.Now())
+}
+
+func TestEngine_ScheduleInThePast(t *testing.T) {
+	e := sim.NewEngine()
+	id := e.Schedule(5, func(*sim.Engine) {})
+	e.Run(4)
+
+	assert.Panics(t, func() { e.Schedule(3, func(*sim.Engine) {}) })
+	assert.Panics(t, func() { e.Reschedule(id, 3) })
+	assert.NotPanics(t, func() { e.Schedule(4, func(*sim.Engine) {}) })
+}
+
+func ExampleEngine() {
+	e := sim.NewEngine()
+
+	// A customer arrives every 4 time units and is served for 3 time units.
+	var arrive func(*sim.Engine)
+	arrive = func(e *sim.Engine) {
+		fmt.Printf("%2.0f: customer arrives\n", e.Now())
+		e.After(3, func(e *sim.Engine) {
+			fmt.Printf("%2.0f: customer leaves\n", e.Now())
+		})
+		e.After(4, arrive)
+	}
+
+	e.Schedule(0, arrive)
+	e.Run(10)
+
+	// Output:
+	//  0: customer arrives
+	//  3: customer leaves
+	//  4: customer arrives
+	//  7: customer leaves
+	//  8: customer arrives
+}
@@ -0,0 +1,73 @@
+package prioqueue
+
+// SortItems sorts the given items in place by their priority using heapsort.
+// Items are sorted in ascending order of their priority unless descending is
+// true. Sorting takes O(n log n) time and does not allocate any memory.
+//
+// Note that heapsort is not stable, i.e. items with equal priority may be
+// reordered.
+func SortItems(items []*Item, descending bool) {
+	if descending {
+		h := &MinHeap{items: items}
+		h.heapify()
+		h.sortInPlace()
+		return
+	}
+
+	h := &MaxHeap{items: items}
+	h.heapify()
+	h.sortInPlace()
+}
+
+// PartialSort moves the k items with the highest priority to the front of the
+// given slice and sorts them in descending order of their priority. The order
+// of the remaining items is unspecified. This takes O(n log k) time and does not
+// allocate any memory.
+//
+// If k is larger than the amount of items, all items are sorted.
+func PartialSort(items []*Item, k int) {
+	if k <= 0 {
+		return
+	}
+	if k >= len(items) {
+		SortItems(items, true)
+		return
+	}
+
+	// Maintain the k highest items seen so far in a MinHeap at the front of the
+	// slice so the smallest of them can be replaced efficiently.
+	h := &MinHeap{items: items[:k]}
+	h.heapify()
+	for i := k; i < len(items); i++ {
+		if items[i].Prio > items[0].Prio {
+			items[0], items[i] = items[i], items[0]
+			h.shiftDown(0)
+		}
+	}
+
+	h.sortInPlace()
+}
+
+// sortInPlace sorts the backing array of the heap in ascending order by
+// repeatedly moving the root node behind the shrinking heap.
+func (h *MaxHeap) sortInPlace() {
+	items := h.items
+	for n := len(items) - 1; n > 0; n-- {
+		items[0], items[n] = items[n], items[0]
+		h.items = items[:n]
+		h.shiftDown(0)
+	}
+	h.items = items
+}
+
+// sortInPlace sorts the backing array of the heap in descending order by
+// repeatedly moving the root node behind the shrinking heap.
+func (h *MinHeap) sortInPlace() {
+	items := h.items
+	for n := len(items) - 1; n > 0; n-- {
+		items[0], items[n] = items[n], items[0]
+		h.items = items[:n]
+		h.shiftDown(0)
+	}
+	h.items = items
+}
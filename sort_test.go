@@ -0,0 +1,60 @@
+package prioqueue_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/stretchr/testify/assert"
+)
+
+func randomItems(n int) []*prioqueue.Item {
+	rng := rand.New(rand.NewSource(42))
+	items := make([]*prioqueue.Item, n)
+	for i := range items {
+		items[i] = &prioqueue.Item{ID: uint32(i), Prio: rng.Float32()}
+	}
+	return items
+}
+
+func TestSortItems(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 10, 1000} {
+		items := randomItems(n)
+		prioqueue.SortItems(items, false)
+		assert.Len(t, items, n)
+		assert.True(t, sort.SliceIsSorted(items, func(i, j int) bool {
+			return items[i].Prio < items[j].Prio
+		}), "items should be sorted in ascending order (n=%d)", n)
+
+		prioqueue.SortItems(items, true)
+		assert.Len(t, items, n)
+		assert.True(t, sort.SliceIsSorted(items, func(i, j int) bool {
+			return items[i].Prio > items[j].Prio
+		}), "items should be sorted in descending order (n=%d)", n)
+	}
+}
+
+func TestPartialSort(t *testing.T) {
+	expected := randomItems(1000)
+	sort.Slice(expected, func(i, j int) bool {
+		return expected[i].Prio > expected[j].Prio
+	})
+
+	for _, k := range []int{0, 1, 2, 10, 999, 1000, 2000} {
+		items := randomItems(1000)
+		prioqueue.PartialSort(items, k)
+
+		n := k
+		if n > len(items) {
+			n = len(items)
+		}
+		assert.Equal(t, expected[:n], items[:n], "top %d items should be sorted at the front", k)
+
+		seen := map[uint32]bool{}
+		for _, item := range items {
+			seen[item.ID] = true
+		}
+		assert.Len(t, seen, len(items), "no item should be lost (k=%d)", k)
+	}
+}
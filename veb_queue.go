@@ -0,0 +1,305 @@
+package prioqueue
+
+import "math/bits"
+
+// vebLeafBits is the largest universe, in bits, which is stored in the bitmap
+// of a single vebNode instead of being split into clusters.
+const vebLeafBits = 6
+
+// VEBQueue implements a priority queue for uint32 keys which allows to
+// retrieve the item with the lowest key. Instead of comparing items, it uses a
+// van Emde Boas tree which splits each key into a high and a low half. The
+// high half selects a cluster which is again a van Emde Boas tree for the low
+// half. An additional summary tree keeps track of the non-empty clusters.
+// Universes of at most 64 keys are stored in a bitmap.
+//
+// Clusters are stored in maps and are only created for keys which are present
+// in the queue, so the memory usage depends on the number of distinct keys
+// rather than on the size of the universe. The map lookups are much slower than
+// the comparisons of a MinHeap though, so the VEBQueue only pays off for very
+// large queues or if Delete and Successor are needed.
+//
+// Multiple items may have the same key. Such items are dequeued in the order
+// in which they were pushed. Their IDs are kept in a FIFO list per key.
+//
+// Time Complexity
+//
+//   Push, Pop and Successor take O(log log U) where U is the size of the
+//   universe, i.e. 2^32. Top happens in constant time. Delete takes O(log log U)
+//   plus O(k) where k is the amount of items with the same key, since the ID
+//   has to be searched in the FIFO list of the key.
+type VEBQueue struct {
+	root vebNode
+	ids  map[uint32]*bucketFIFO // the IDs of all items with a key, in push order
+	len  int
+}
+
+// vebNode is a van Emde Boas tree for a universe of 2^bits keys. The minimum
+// of a tree is not stored in its clusters.
+type vebNode struct {
+	bits     uint8
+	bitmap   uint64 // all keys of a leaf node
+	nonEmpty bool
+	min, max uint32
+	summary  *vebNode
+	clusters map[uint32]*vebNode
+}
+
+// NewVEBQueue returns a new VEBQueue instance. The zero value of a VEBQueue is
+// also ready to use.
+func NewVEBQueue() *VEBQueue {
+	return new(VEBQueue)
+}
+
+// Len returns the amount of elements in the queue.
+func (q *VEBQueue) Len() int {
+	return q.len
+}
+
+// Reset empties the queue. All memory which is held by the queue is released.
+func (q *VEBQueue) Reset() {
+	q.root = vebNode{}
+	q.ids = nil
+	q.len = 0
+}
+
+// Push adds an item with the given ID and key to the queue.
+func (q *VEBQueue) Push(id, key uint32) {
+	if q.ids == nil {
+		q.ids = map[uint32]*bucketFIFO{}
+		q.root.bits = 32
+	}
+
+	ids, ok := q.ids[key]
+	if !ok {
+		ids = new(bucketFIFO)
+		q.ids[key] = ids
+		q.root.insert(key)
+	}
+
+	ids.push(id)
+	q.len++
+}
+
+// Top returns the ID and key of the item with the lowest key in the queue
+// without removing it.
+func (q *VEBQueue) Top() (id, key uint32) {
+	if q.len == 0 {
+		return 0, 0
+	}
+
+	key = q.root.minimum()
+	ids := q.ids[key]
+	return ids.ids[ids.head], key
+}
+
+// Pop removes the item with the lowest key from the queue and returns its ID
+// and key.
+func (q *VEBQueue) Pop() (id, key uint32) {
+	if q.len == 0 {
+		return 0, 0
+	}
+
+	key = q.root.minimum()
+	ids := q.ids[key]
+	id = ids.pop()
+	q.removed(key, ids)
+	return id, key
+}
+
+// Delete removes the item with the given ID and key from the queue. It returns
+// false if there is no such item. If there are multiple such items, the one
+// that was pushed first is removed.
+func (q *VEBQueue) Delete(id, key uint32) bool {
+	ids, ok := q.ids[key]
+	if !ok {
+		return false
+	}
+
+	for i := ids.head; i < len(ids.ids); i++ {
+		if ids.ids[i] == id {
+			// Move the IDs before i back by one so the order is kept.
+			copy(ids.ids[ids.head+1:i+1], ids.ids[ids.head:i])
+			ids.pop()
+			q.removed(key, ids)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Successor returns the smallest key in the queue which is larger than the
+// given key. It returns false if there is no such key.
+func (q *VEBQueue) Successor(key uint32) (uint32, bool) {
+	if q.len == 0 {
+		return 0, false
+	}
+
+	return q.root.successor(key)
+}
+
+// removed updates the queue after an ID was removed from the FIFO list of the
+// given key.
+func (q *VEBQueue) removed(key uint32, ids *bucketFIFO) {
+	if ids.len() == 0 {
+		delete(q.ids, key)
+		q.root.delete(key)
+	}
+
+	q.len--
+}
+
+// newVEBNode returns a new empty tree for a universe of 2^bits keys.
+func newVEBNode(bits uint8) *vebNode {
+	return &vebNode{bits: bits}
+}
+
+func (n *vebNode) leaf() bool {
+	return n.bits <= vebLeafBits
+}
+
+func (n *vebNode) empty() bool {
+	if n.leaf() {
+		return n.bitmap == 0
+	}
+	return !n.nonEmpty
+}
+
+// minimum returns the smallest key of the non-empty tree.
+func (n *vebNode) minimum() uint32 {
+	if n.leaf() {
+		return uint32(bits.TrailingZeros64(n.bitmap))
+	}
+	return n.min
+}
+
+// maximum returns the largest key of the non-empty tree.
+func (n *vebNode) maximum() uint32 {
+	if n.leaf() {
+		return uint32(63 - bits.LeadingZeros64(n.bitmap))
+	}
+	return n.max
+}
+
+// split returns the cluster of key x and the position of x in that cluster.
+func (n *vebNode) split(x uint32) (hi, lo uint32) {
+	low := n.bits / 2
+	return x >> low, x & (1<<low - 1)
+}
+
+// join is the inverse of split.
+func (n *vebNode) join(hi, lo uint32) uint32 {
+	return hi<<(n.bits/2) | lo
+}
+
+// insert adds the key x, which must not be in the tree yet.
+func (n *vebNode) insert(x uint32) {
+	if n.leaf() {
+		n.bitmap |= 1 << x
+		return
+	}
+
+	if !n.nonEmpty {
+		n.min, n.max = x, x
+		n.nonEmpty = true
+		return
+	}
+
+	// The minimum is not stored in a cluster, so if x becomes the new
+	// minimum, the old minimum is inserted into the clusters instead.
+	if x < n.min {
+		x, n.min = n.min, x
+	}
+	if x > n.max {
+		n.max = x
+	}
+
+	hi, lo := n.split(x)
+	c, ok := n.clusters[hi]
+	if !ok {
+		if n.clusters == nil {
+			n.clusters = map[uint32]*vebNode{}
+			n.summary = newVEBNode(n.bits - n.bits/2)
+		}
+
+		c = newVEBNode(n.bits / 2)
+		n.clusters[hi] = c
+		n.summary.insert(hi)
+	}
+
+	c.insert(lo)
+}
+
+// delete removes the key x, which must be in the tree.
+func (n *vebNode) delete(x uint32) {
+	if n.leaf() {
+		n.bitmap &^= 1 << x
+		return
+	}
+
+	if n.min == n.max {
+		n.nonEmpty = false
+		return
+	}
+
+	if x == n.min {
+		// The smallest key of the clusters becomes the new minimum and is
+		// removed from its cluster.
+		hi := n.summary.minimum()
+		x = n.join(hi, n.clusters[hi].minimum())
+		n.min = x
+	}
+
+	hi, lo := n.split(x)
+	c := n.clusters[hi]
+	c.delete(lo)
+	if c.empty() {
+		delete(n.clusters, hi)
+		n.summary.delete(hi)
+		if n.summary.empty() {
+			// Release the memory of sparse trees as soon as possible.
+			n.clusters, n.summary = nil, nil
+		}
+	}
+
+	if x == n.max {
+		if n.summary == nil {
+			n.max = n.min
+		} else {
+			hi := n.summary.maximum()
+			n.max = n.join(hi, n.clusters[hi].maximum())
+		}
+	}
+}
+
+// successor returns the smallest key in the tree which is larger than x.
+func (n *vebNode) successor(x uint32) (uint32, bool) {
+	if n.leaf() {
+		if x >= 63 {
+			return 0, false
+		}
+		m := n.bitmap >> (x + 1) << (x + 1)
+		if m == 0 {
+			return 0, false
+		}
+		return uint32(bits.TrailingZeros64(m)), true
+	}
+
+	if !n.nonEmpty || x >= n.max {
+		return 0, false
+	}
+	if x < n.min {
+		return n.min, true
+	}
+
+	hi, lo := n.split(x)
+	if c, ok := n.clusters[hi]; ok && lo < c.maximum() {
+		s, _ := c.successor(lo)
+		return n.join(hi, s), true
+	}
+
+	// x < max, so there must be a non-empty cluster after the one of x.
+	hi, _ = n.summary.successor(hi)
+	return n.join(hi, n.clusters[hi].minimum()), true
+}
@@ -0,0 +1,175 @@
+package prioqueue_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/fgrosse/prioqueue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVEBQueue(t *testing.T) {
+	var q prioqueue.VEBQueue
+
+	id, key := q.Pop()
+	assert.EqualValues(t, 0, id)
+	assert.EqualValues(t, 0, key)
+	id, key = q.Top()
+	assert.EqualValues(t, 0, id)
+	assert.EqualValues(t, 0, key)
+	_, ok := q.Successor(0)
+	assert.False(t, ok)
+
+	keys := []uint32{50, 10, 1 << 31, 0, 10, 1<<32 - 1, 65536, 20}
+	for i, key := range keys {
+		q.Push(uint32(i), key)
+	}
+	require.Equal(t, len(keys), q.Len())
+
+	// Items with equal keys are returned in push order.
+	expected := []struct{ id, key uint32 }{
+		{3, 0}, {1, 10}, {4, 10}, {7, 20}, {0, 50}, {6, 65536}, {2, 1 << 31}, {5, 1<<32 - 1},
+	}
+	for _, e := range expected {
+		topID, topKey := q.Top()
+		id, key := q.Pop()
+		assert.Equal(t, topID, id)
+		assert.Equal(t, topKey, key)
+		assert.Equal(t, e.id, id)
+		assert.Equal(t, e.key, key)
+	}
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestVEBQueue_Delete(t *testing.T) {
+	q := prioqueue.NewVEBQueue()
+	q.Push(1, 100)
+	q.Push(2, 100)
+	q.Push(3, 5)
+
+	assert.False(t, q.Delete(1, 5), "the ID does not have this key")
+	assert.False(t, q.Delete(4, 100))
+	assert.True(t, q.Delete(1, 100))
+	assert.False(t, q.Delete(1, 100), "the item was already deleted")
+	assert.Equal(t, 2, q.Len())
+
+	assert.True(t, q.Delete(3, 5))
+	id, key := q.Top()
+	assert.EqualValues(t, 2, id)
+	assert.EqualValues(t, 100, key)
+
+	q.Reset()
+	assert.Equal(t, 0, q.Len())
+	q.Push(7, 3)
+	id, key = q.Pop()
+	assert.EqualValues(t, 7, id)
+	assert.EqualValues(t, 3, key)
+}
+
+func TestVEBQueue_SameKey(t *testing.T) {
+	q := prioqueue.NewVEBQueue()
+
+	// Draining many items with the same key must not take quadratic time.
+	const n = 200_000
+	for i := uint32(0); i < n; i++ {
+		q.Push(i, 42)
+	}
+
+	assert.True(t, q.Delete(5, 42))
+	assert.True(t, q.Delete(n-1, 42))
+	q.Push(n, 42)
+
+	for i := uint32(0); i < n; i++ {
+		if i == 5 {
+			continue
+		}
+
+		expected := i
+		if i == n-1 {
+			expected = n
+		}
+
+		id, key := q.Pop()
+		require.Equal(t, expected, id, "items with the same key should be dequeued in push order")
+		require.EqualValues(t, 42, key)
+	}
+	assert.Equal(t, 0, q.Len())
+}
+
+func TestVEBQueue_Random(t *testing.T) {
+	q := prioqueue.NewVEBQueue()
+	rng := rand.New(rand.NewSource(42))
+
+	// keys contains the key of each item that is in the queue.
+	keys := map[uint32]uint32{}
+	randKey := func() uint32 {
+		// Mix dense and sparse keys so clusters of all levels are created
+		// and removed again.
+		if rng.Intn(2) == 0 {
+			return uint32(rng.Intn(300))
+		}
+		return rng.Uint32()
+	}
+
+	for i := 0; i < 50_000; i++ {
+		switch rng.Intn(4) {
+		case 0, 1:
+			key := randKey()
+			q.Push(uint32(i), key)
+			keys[uint32(i)] = key
+
+		case 2:
+			id, key := q.Pop()
+			if len(keys) == 0 {
+				require.Equal(t, 0, q.Len())
+				continue
+			}
+			require.Equal(t, keys[id], key)
+			delete(keys, id)
+			for _, k := range keys {
+				require.GreaterOrEqual(t, k, key)
+			}
+
+		case 3:
+			for id, key := range keys {
+				require.True(t, q.Delete(id, key))
+				delete(keys, id)
+				break
+			}
+		}
+		require.Equal(t, len(keys), q.Len())
+
+		if i%100 == 0 {
+			checkSuccessors(t, q, keys, randKey())
+		}
+	}
+}
+
+// checkSuccessors checks the successors of x in the queue which contains the
+// given keys.
+func checkSuccessors(t *testing.T, q *prioqueue.VEBQueue, keys map[uint32]uint32, x uint32) {
+	t.Helper()
+
+	var sorted []uint32
+	for _, k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	i := sort.Search(len(sorted), func(i int) bool { return sorted[i] > x })
+	for ; i < len(sorted); i++ {
+		if i > 0 && sorted[i] == sorted[i-1] {
+			continue
+		}
+
+		s, ok := q.Successor(x)
+		require.True(t, ok)
+		require.Equal(t, sorted[i], s)
+		x = s
+	}
+
+	_, ok := q.Successor(x)
+	require.False(t, ok)
+}